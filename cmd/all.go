@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/sw33tLie/bbscope/internal/utils"
+	"github.com/sw33tLie/bbscope/pkg/platforms"
+)
+
+// allCmd represents the all command
+var allCmd = &cobra.Command{
+	Use:   "all",
+	Short: "All configured platforms",
+	Long:  "Gathers data from every platform whose credentials are set in the config file, at the same time",
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := getOptions(cmd)
+
+		// Targets from different platforms are mixed together, so always tell them apart
+		outputFlags, _ := rootCmd.PersistentFlags().GetString("output")
+		if !strings.Contains(outputFlags, "P") {
+			outputFlags = "P" + outputFlags
+		}
+		ps := configuredPlatforms(platforms.All())
+		// Platforms needing no credentials are always configured, so they don't count
+		authenticated := 0
+		for _, p := range ps {
+			if len(p.AuthFields()) > 0 {
+				authenticated++
+			}
+		}
+		if authenticated == 0 {
+			log.Fatal("No platform credentials in the config file, please set them first")
+		}
+		writer := withStore(newWriter(outputFlags), ps, opts)
+
+		setupProxy()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		printResults(ctx, streamPlatforms(ctx, ps, opts), writer)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(allCmd)
+}
+
+// configuredPlatforms returns the platforms among ps whose credentials are set in the config file
+func configuredPlatforms(ps []platforms.Platform) []platforms.Platform {
+	var configured []platforms.Platform
+	for _, p := range ps {
+		if _, ok := configCredentials(p); !ok {
+			utils.Log.Infof("Skipping %s: no credentials in the config file", p.DisplayName())
+			continue
+		}
+		configured = append(configured, p)
+	}
+	return configured
+}
+
+// streamPlatforms fetches the scope of every platform in ps at the same time, with the
+// credentials set in the config file, and merges the results in a single stream
+func streamPlatforms(ctx context.Context, ps []platforms.Platform, opts platforms.Options) <-chan platforms.Result {
+	var streams []<-chan platforms.Result
+	for _, p := range ps {
+		creds, _ := configCredentials(p)
+		utils.Log.Debugf("Fetching %s", p.DisplayName())
+		streams = append(streams, platforms.StreamAllProgramsScope(ctx, p, creds, opts, p.DefaultConcurrency()))
+	}
+	return mergeResults(streams...)
+}
+
+// configCredentials reads the platform's credentials from the config file. It reports
+// false if a required credential is missing, or if the platform needs credentials and
+// none of them is set.
+func configCredentials(p platforms.Platform) (platforms.Credentials, bool) {
+	creds := make(platforms.Credentials)
+	found := false
+	for _, field := range p.AuthFields() {
+		value := ""
+		if field.ConfigKey != "" {
+			value = viper.GetViper().GetString(field.ConfigKey)
+		}
+
+		if value == "" && field.Required {
+			return nil, false
+		}
+		if value != "" {
+			found = true
+		}
+		creds[field.Name] = value
+	}
+	return creds, found || len(p.AuthFields()) == 0
+}
+
+// mergeResults forwards the results of every stream to a single channel, which is
+// closed once all streams are. Each stream keeps its own order.
+func mergeResults(streams ...<-chan platforms.Result) <-chan platforms.Result {
+	merged := make(chan platforms.Result)
+	wg := new(sync.WaitGroup)
+	wg.Add(len(streams))
+
+	for _, stream := range streams {
+		go func(stream <-chan platforms.Result) {
+			defer wg.Done()
+			for r := range stream {
+				merged <- r
+			}
+		}(stream)
+	}
+
+	go func() {
+		wg.Wait()
+		close(merged)
+	}()
+
+	return merged
+}
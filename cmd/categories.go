@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/sw33tLie/bbscope/pkg/platforms"
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+// categoriesCmd represents the categories command
+var categoriesCmd = &cobra.Command{
+	Use:   "categories",
+	Short: "Show the scope categories and what they match on each platform",
+	Long:  "Shows the categories accepted by the -c flag and the asset types they match on each platform",
+	Run: func(cmd *cobra.Command, args []string) {
+		allPlatforms := platforms.All()
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+		header := "CATEGORY"
+		for _, p := range allPlatforms {
+			header += "\t" + p.Name()
+		}
+		fmt.Fprintln(w, header)
+
+		for _, category := range scope.Categories {
+			line := category
+			for _, p := range allPlatforms {
+				assetTypes := p.Categories()[category]
+				if len(assetTypes) == 0 {
+					line += "\t-"
+				} else {
+					line += "\t" + strings.Join(assetTypes, ", ")
+				}
+			}
+			fmt.Fprintln(w, line)
+		}
+		w.Flush()
+
+		fmt.Println()
+		fmt.Println("Targets of the asset types listed under url are reported as wildcard when they contain a wildcard.")
+
+		groups := make([]string, 0, len(scope.CategoryGroups))
+		for group := range scope.CategoryGroups {
+			groups = append(groups, group)
+		}
+		sort.Strings(groups)
+
+		fmt.Println()
+		fmt.Println("Groups:")
+		for _, group := range groups {
+			fmt.Printf("  %s: %s\n", group, strings.Join(scope.CategoryGroups[group], ", "))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(categoriesCmd)
+}
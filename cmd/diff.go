@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/signal"
+	"reflect"
+
+	"github.com/spf13/cobra"
+	"github.com/sw33tLie/bbscope/internal/utils"
+	"github.com/sw33tLie/bbscope/pkg/diff"
+	"github.com/sw33tLie/bbscope/pkg/platforms"
+	"github.com/sw33tLie/bbscope/pkg/scope"
+	"github.com/sw33tLie/bbscope/pkg/store"
+)
+
+// Exit codes of the diff command
+const (
+	DIFF_EXIT_UNCHANGED = 0
+	DIFF_EXIT_CHANGED   = 1
+	DIFF_EXIT_ERROR     = 2
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show what changed between two runs",
+	Long: `Compares two runs saved in the store, or the latest run with a fresh fetch of the same platforms, and reports new and removed programs, added and removed targets, and changed descriptions, bounty eligibility and categories.
+
+Exits with 0 if nothing changed, 1 if something did and 2 on errors.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fromID, _ := cmd.Flags().GetString("from")
+		toID, _ := cmd.Flags().GetString("to")
+		format, _ := rootCmd.PersistentFlags().GetString("format")
+
+		if format != "text" && format != "json" {
+			diffFatalf("Invalid format %q for diff, valid choices are: text, json", format)
+		}
+
+		s, err := openStore()
+		if err != nil {
+			diffFatalf("%v", err)
+		}
+
+		if fromID == "" {
+			run, err := s.LatestRun("")
+			if err != nil {
+				diffFatalf("Can't find a run to compare with: %v", err)
+			}
+			fromID = run.ID
+		}
+		from, err := loadSnapshot(s, fromID)
+		if err != nil {
+			diffFatalf("%v", err)
+		}
+
+		var to snapshot
+		if toID != "" {
+			to, err = loadSnapshot(s, toID)
+			if err != nil {
+				diffFatalf("%v", err)
+			}
+		} else {
+			var ps []platforms.Platform
+			for _, name := range from.Platforms {
+				if p, ok := platforms.Get(name); ok {
+					ps = append(ps, p)
+				}
+			}
+
+			setupProxy()
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			// Fetch with the same filters as the run we compare with
+			to, err = fetchSnapshot(ctx, configuredPlatforms(ps), from.Options)
+			if err != nil {
+				diffFatalf("%v", err)
+			}
+			if ctx.Err() != nil {
+				diffFatalf("%v", ctx.Err())
+			}
+		}
+
+		changes := compareSnapshots(from, to)
+
+		if format == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if changes == nil {
+				changes = []diff.ProgramChange{}
+			}
+			err = encoder.Encode(struct {
+				From    string               `json:"from"`
+				To      string               `json:"to"`
+				Changes []diff.ProgramChange `json:"changes"`
+			}{from.ID, to.ID, changes})
+		} else {
+			err = diff.WriteText(os.Stdout, changes)
+		}
+		if err != nil {
+			diffFatalf("%v", err)
+		}
+
+		if len(changes) > 0 {
+			os.Exit(DIFF_EXIT_CHANGED)
+		}
+		os.Exit(DIFF_EXIT_UNCHANGED)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().StringP("from", "", "", "ID of the older run (default: the latest run)")
+	diffCmd.Flags().StringP("to", "", "", "ID of the newer run (default: fetch the platforms of the older run again)")
+}
+
+func diffFatalf(format string, args ...interface{}) {
+	utils.Log.Errorf(format, args...)
+	os.Exit(DIFF_EXIT_ERROR)
+}
+
+// snapshot holds the programs fetched by a run
+type snapshot struct {
+	// ID is the run ID, empty for fetches that were not saved to the store
+	ID        string
+	Platforms []string
+	Options   platforms.Options
+	Programs  []scope.ProgramData
+	// Failed lists, per platform, the programs that could not be fetched. An empty
+	// handle means the platform could not be listed at all.
+	Failed map[string][]string
+}
+
+func loadSnapshot(s *store.Store, runID string) (snapshot, error) {
+	run, err := s.Run(runID)
+	if err != nil {
+		return snapshot{}, err
+	}
+	programs, err := s.Programs(run.ID, "")
+	if err != nil {
+		return snapshot{}, err
+	}
+	return snapshot{ID: run.ID, Platforms: run.Platforms, Options: run.Options, Programs: programs, Failed: run.Failed}, nil
+}
+
+// fetchSnapshot fetches the platforms in ps and saves the run to the store. Programs that
+// could not be fetched are logged and recorded in the snapshot, an error is only returned
+// if the run could not be started in the store.
+func fetchSnapshot(ctx context.Context, ps []platforms.Platform, opts platforms.Options) (snapshot, error) {
+	snap := snapshot{Options: opts, Failed: make(map[string][]string)}
+	for _, p := range ps {
+		snap.Platforms = append(snap.Platforms, p.Name())
+	}
+
+	run, err := newStoreRun(ps, opts)
+	if err != nil {
+		return snapshot{}, err
+	}
+	if run != nil {
+		snap.ID = run.Run().ID
+	}
+
+	for r := range streamPlatforms(ctx, ps, opts) {
+		if r.Err != nil {
+			utils.Log.Error(r.Err)
+			snap.Failed[r.Platform] = append(snap.Failed[r.Platform], r.Handle)
+			if run != nil {
+				run.WriteError(r.Platform, r.Handle, r.Err)
+			}
+			continue
+		}
+
+		snap.Programs = append(snap.Programs, r.Program)
+		if run != nil {
+			if err := run.WriteProgram(r.Program); err != nil {
+				utils.Log.Error(err)
+			}
+		}
+	}
+
+	// Interrupted runs are left unfinished
+	if run != nil && ctx.Err() == nil {
+		if err := run.Close(); err != nil {
+			utils.Log.Error(err)
+		}
+	}
+	return snap, nil
+}
+
+// compareSnapshots returns the changes between two snapshots. Only platforms fetched by both
+// are compared, and programs that could not be fetched by either are ignored, so that fetch
+// errors are not reported as removed programs.
+func compareSnapshots(from snapshot, to snapshot) []diff.ProgramChange {
+	if !reflect.DeepEqual(from.Options, to.Options) {
+		utils.Log.Warn("The runs were fetched with different filters, some changes may only come from that")
+	}
+
+	skip := make(map[string]bool)
+	for _, snap := range []snapshot{from, to} {
+		for platform, handles := range snap.Failed {
+			for _, handle := range handles {
+				skip[platform+"|"+handle] = true
+			}
+		}
+	}
+
+	comparable := func(pData scope.ProgramData, other snapshot) bool {
+		return containsString(other.Platforms, pData.Platform) && !skip[pData.Platform+"|"] && !skip[pData.Platform+"|"+pData.Handle]
+	}
+
+	var old, new []scope.ProgramData
+	for _, pData := range from.Programs {
+		if comparable(pData, to) {
+			old = append(old, pData)
+		}
+	}
+	for _, pData := range to.Programs {
+		if comparable(pData, from) {
+			new = append(new, pData)
+		}
+	}
+
+	if len(skip) > 0 {
+		utils.Log.Warnf("%d program(s) or platform(s) could not be fetched and were not compared", len(skip))
+	}
+	return diff.Compare(old, new)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
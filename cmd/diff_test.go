@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sw33tLie/bbscope/pkg/diff"
+	"github.com/sw33tLie/bbscope/pkg/platforms"
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+func testProgram(platform string, handle string, targets ...string) scope.ProgramData {
+	pData := scope.ProgramData{Platform: platform, Handle: handle, Url: "https://example.com/" + handle}
+	for _, target := range targets {
+		pData.InScope = append(pData.InScope, scope.ScopeElement{Target: target, Category: "url"})
+	}
+	return pData
+}
+
+// changeKeys returns platform|handle|status for each change
+func changeKeys(changes []diff.ProgramChange) []string {
+	var keys []string
+	for _, change := range changes {
+		keys = append(keys, change.Platform+"|"+change.Handle+"|"+change.Status)
+	}
+	return keys
+}
+
+func TestCompareSnapshots(t *testing.T) {
+	from := snapshot{
+		Platforms: []string{"h1", "bc"},
+		Programs: []scope.ProgramData{
+			testProgram("h1", "a", "a.com"),
+			testProgram("h1", "b", "b.com"),
+			testProgram("bc", "c", "c.com"),
+		},
+	}
+
+	tests := []struct {
+		name string
+		to   snapshot
+		want []string
+	}{
+		{
+			name: "all fetched",
+			to: snapshot{
+				Platforms: []string{"h1", "bc"},
+				Programs: []scope.ProgramData{
+					testProgram("h1", "a", "a.com", "api.a.com"),
+					testProgram("bc", "c", "c.com"),
+					testProgram("bc", "d", "d.com"),
+				},
+			},
+			want: []string{"bc|d|added", "h1|a|changed", "h1|b|removed"},
+		},
+		{
+			name: "platform not fetched by both",
+			to: snapshot{
+				Platforms: []string{"h1", "it"},
+				Programs: []scope.ProgramData{
+					testProgram("h1", "a", "a.com"),
+					testProgram("h1", "b", "b.com"),
+					testProgram("it", "e", "e.com"),
+				},
+			},
+		},
+		{
+			name: "failed program",
+			to: snapshot{
+				Platforms: []string{"h1", "bc"},
+				Programs: []scope.ProgramData{
+					testProgram("h1", "a", "a.com", "api.a.com"),
+					testProgram("bc", "c", "c.com"),
+				},
+				Failed: map[string][]string{"h1": {"b"}},
+			},
+			want: []string{"h1|a|changed"},
+		},
+		{
+			name: "failed program comes back",
+			to: snapshot{
+				Platforms: []string{"h1", "bc"},
+				Programs: []scope.ProgramData{
+					testProgram("h1", "a", "a.com"),
+					testProgram("h1", "b", "b.com", "api.b.com"),
+					testProgram("bc", "c", "c.com"),
+				},
+				Failed: map[string][]string{"bc": {"c"}},
+			},
+			want: []string{"h1|b|changed"},
+		},
+		{
+			name: "failed listing",
+			to: snapshot{
+				Platforms: []string{"h1", "bc"},
+				Programs: []scope.ProgramData{
+					testProgram("bc", "c", "c.com"),
+					testProgram("bc", "d", "d.com"),
+				},
+				Failed: map[string][]string{"h1": {""}},
+			},
+			want: []string{"bc|d|added"},
+		},
+		{
+			name: "different options",
+			to: snapshot{
+				Platforms: []string{"h1", "bc"},
+				Options:   platforms.Options{BbpOnly: true},
+				Programs: []scope.ProgramData{
+					testProgram("h1", "a", "a.com"),
+					testProgram("bc", "c", "c.com"),
+				},
+			},
+			want: []string{"h1|b|removed"},
+		},
+	}
+
+	for _, tt := range tests {
+		got := changeKeys(compareSnapshots(from, tt.to))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSnapshotCarryOver(t *testing.T) {
+	previous := snapshot{
+		Platforms: []string{"h1"},
+		Programs:  []scope.ProgramData{testProgram("h1", "a", "a.com"), testProgram("h1", "b", "b.com")},
+	}
+	current := snapshot{
+		Platforms: []string{"h1"},
+		Programs:  []scope.ProgramData{testProgram("h1", "a", "a.com")},
+		Failed:    map[string][]string{"h1": {"b", "c"}},
+	}
+
+	carried := current.carryOver(previous)
+	if got := changeKeys(compareSnapshots(previous, carried)); got != nil {
+		t.Errorf("carried over program reported as changed: %v", got)
+	}
+	if !reflect.DeepEqual(carried.Failed, map[string][]string{"h1": {"c"}}) {
+		t.Errorf("got failed %v, want only the program without previous data", carried.Failed)
+	}
+
+	next := snapshot{
+		Platforms: []string{"h1"},
+		Programs:  []scope.ProgramData{testProgram("h1", "a", "a.com"), testProgram("h1", "b", "b.com", "api.b.com"), testProgram("h1", "c", "c.com")},
+	}
+	// c has no baseline yet, so it is not reported as added
+	want := []string{"h1|b|changed"}
+	if got := changeKeys(compareSnapshots(carried, next)); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"io"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/sw33tLie/bbscope/internal/utils"
+	"github.com/sw33tLie/bbscope/pkg/diff"
+	"github.com/sw33tLie/bbscope/pkg/feed"
+	"github.com/sw33tLie/bbscope/pkg/platforms"
+	"github.com/sw33tLie/bbscope/pkg/scope"
+	"github.com/sw33tLie/bbscope/pkg/store"
+)
+
+// feedCmd represents the feed command
+var feedCmd = &cobra.Command{
+	Use:   "feed",
+	Short: "Write an Atom or RSS feed of the scope changes in the store",
+	Long: `Compares every run saved in the store with the previous run of the same platform, fetched with the same filters, and writes the changes as an Atom and/or RSS feed, newest first.
+-b, -p and -c select which changes go in the feed: only bounty programs, only private programs, only targets of some categories.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		atomPath, _ := cmd.Flags().GetString("atom")
+		rssPath, _ := cmd.Flags().GetString("rss")
+		since, _ := cmd.Flags().GetDuration("since")
+		limit, _ := cmd.Flags().GetInt("limit")
+
+		if atomPath == "" && rssPath == "" {
+			log.Fatal("Nothing to write, please provide an Atom or RSS output path")
+		}
+
+		opts := getOptions(cmd)
+		categories, _ := scope.ParseCategories(opts.Categories)
+		filter := diff.Filter{BountyOnly: opts.BbpOnly, PrivateOnly: opts.PvtOnly, Categories: categories}
+
+		var from time.Time
+		if since > 0 {
+			from = time.Now().Add(-since)
+		}
+
+		s, err := openStore()
+		if err != nil {
+			log.Fatal(err)
+		}
+		runs, err := s.Runs()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		events := filter.ApplyEvents(storedEvents(s, runs, from))
+		sort.SliceStable(events, func(i, j int) bool { return events[i].Time.After(events[j].Time) })
+		if limit > 0 && len(events) > limit {
+			events = events[:limit]
+		}
+
+		updated := time.Now()
+		if len(events) > 0 {
+			updated = events[0].Time
+		}
+
+		for _, file := range []struct {
+			path  string
+			write func(w io.Writer, events []diff.Event, updated time.Time) error
+		}{
+			{atomPath, feed.WriteAtom},
+			{rssPath, feed.WriteRSS},
+		} {
+			if file.path == "" {
+				continue
+			}
+
+			f, err := os.Create(file.path)
+			if err != nil {
+				log.Fatal(err)
+			}
+			err = file.write(f, events, updated)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				log.Fatal(err)
+			}
+			utils.Log.Infof("Feed with %d entries written to %s", len(events), file.path)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(feedCmd)
+	feedCmd.Flags().StringP("atom", "", "bbscope.atom", "Atom feed path, empty to skip it")
+	feedCmd.Flags().StringP("rss", "", "", "RSS feed path, empty to skip it")
+	feedCmd.Flags().DurationP("since", "", 0, "Only include changes found in this last period (e.g. 720h), default: all")
+	feedCmd.Flags().IntP("limit", "", 100, "Maximum number of entries, 0 for no limit")
+}
+
+// baselineKey identifies the runs that can be compared with each other
+type baselineKey struct {
+	platform string
+	opts     platforms.Options
+}
+
+// storedEvents compares each finished run in runs with the previous one of the same platform
+// and filters, and returns the changes found by the runs finished after from
+func storedEvents(s *store.Store, runs []store.Run, from time.Time) []diff.Event {
+	// Runs finished before from are only needed as baselines, so only the last of them is loaded
+	lastBefore := make(map[baselineKey]string)
+	for _, run := range runs {
+		if run.Finished() && run.FinishedAt.Before(from) {
+			for _, platform := range run.Platforms {
+				lastBefore[baselineKey{platform, run.Options}] = run.ID
+			}
+		}
+	}
+
+	var events []diff.Event
+	previous := make(map[baselineKey]snapshot)
+	for _, run := range runs {
+		if !run.Finished() {
+			continue
+		}
+
+		var wanted []string
+		for _, platform := range run.Platforms {
+			if !run.FinishedAt.Before(from) || lastBefore[baselineKey{platform, run.Options}] == run.ID {
+				wanted = append(wanted, platform)
+			}
+		}
+		if len(wanted) == 0 {
+			continue
+		}
+
+		snap, err := loadSnapshot(s, run.ID)
+		if err != nil {
+			utils.Log.Warnf("Skipping run %s: %v", run.ID, err)
+			continue
+		}
+
+		for _, platform := range wanted {
+			current := snap.only(platform)
+			if current.listingFailed(platform) {
+				continue
+			}
+
+			key := baselineKey{platform, run.Options}
+			if prev, ok := previous[key]; ok {
+				if !run.FinishedAt.Before(from) {
+					events = append(events, diff.Events(compareSnapshots(prev, current), prev.ID, current.ID, run.FinishedAt)...)
+				}
+				current = current.carryOver(prev)
+			}
+			previous[key] = current
+		}
+	}
+	return events
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/sw33tLie/bbscope/pkg/diff"
+	"github.com/sw33tLie/bbscope/pkg/notify"
+)
+
+// notifyCmd represents the notify command
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Send change events to the configured webhooks",
+	Long: `Reads change events, as printed by bbscope watch, from a file or stdin and sends them to the webhooks configured in the config file or with --webhook.
+Useful to test webhooks, or to resend events.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		eventsFile, _ := cmd.Flags().GetString("events-file")
+
+		notifier := newNotifier(cmd)
+		if notifier.Empty() {
+			log.Fatal("No webhook configured")
+		}
+
+		var in io.Reader = os.Stdin
+		if eventsFile != "" {
+			f, err := os.Open(eventsFile)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer f.Close()
+			in = f
+		}
+
+		var events []diff.Event
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(nil, 64<<20)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			var event diff.Event
+			if err := json.Unmarshal([]byte(line), &event); err != nil {
+				log.Fatalf("Invalid event %q: %v", line, err)
+			}
+			events = append(events, event)
+		}
+		if err := scanner.Err(); err != nil {
+			log.Fatal(err)
+		}
+
+		setupProxy()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		if err := notifier.Notify(ctx, events); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(notifyCmd)
+	notifyCmd.Flags().StringP("events-file", "", "", "JSON lines file to read events from (default: stdin)")
+	addWebhookFlag(notifyCmd)
+}
+
+// addWebhookFlag adds the --webhook flag, read by newNotifier
+func addWebhookFlag(cmd *cobra.Command) {
+	cmd.Flags().StringArrayP("webhook", "", nil, "Webhook to send change events to, as type=url with type one of slack, discord, json (repeatable)")
+}
+
+// newNotifier returns a Notifier sending to the webhooks in the config file and the ones passed with --webhook
+func newNotifier(cmd *cobra.Command) *notify.Notifier {
+	var destinations []notify.Destination
+	if err := viper.UnmarshalKey("webhooks", &destinations); err != nil {
+		log.Fatalf("Invalid webhooks in config file: %v", err)
+	}
+
+	webhooks, _ := cmd.Flags().GetStringArray("webhook")
+	for _, webhook := range webhooks {
+		destinationType, url, ok := strings.Cut(webhook, "=")
+		if !ok {
+			log.Fatalf("Invalid webhook %q, expected type=url", webhook)
+		}
+		destinations = append(destinations, notify.Destination{Type: destinationType, URL: url})
+	}
+
+	notifier, err := notify.New(destinations, &http.Client{Timeout: 30 * time.Second})
+	if err != nil {
+		log.Fatal(err)
+	}
+	return notifier
+}
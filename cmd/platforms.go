@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/sw33tLie/bbscope/internal/utils"
+	"github.com/sw33tLie/bbscope/pkg/output"
+	"github.com/sw33tLie/bbscope/pkg/platforms"
+	"github.com/sw33tLie/bbscope/pkg/scope"
+	"github.com/sw33tLie/bbscope/pkg/sqlite"
+
+	// Supported platforms register themselves with the platforms package
+	_ "github.com/sw33tLie/bbscope/pkg/platforms/bugcrowd"
+	_ "github.com/sw33tLie/bbscope/pkg/platforms/hackerone"
+	_ "github.com/sw33tLie/bbscope/pkg/platforms/immunefi"
+	_ "github.com/sw33tLie/bbscope/pkg/platforms/intigriti"
+	_ "github.com/sw33tLie/bbscope/pkg/platforms/yeswehack"
+)
+
+func init() {
+	for _, p := range platforms.All() {
+		rootCmd.AddCommand(newPlatformCmd(p))
+	}
+}
+
+// newPlatformCmd builds the subcommand for a registered platform
+func newPlatformCmd(p platforms.Platform) *cobra.Command {
+	platformCmd := &cobra.Command{
+		Use:   p.Name(),
+		Short: p.DisplayName(),
+		Long:  "Gathers data from " + p.DisplayName() + " (" + p.URL() + ")",
+		Run: func(cmd *cobra.Command, args []string) {
+			creds := getCredentials(cmd, p)
+			opts := getOptions(cmd)
+			concurrency, _ := cmd.Flags().GetInt("concurrency")
+
+			outputFlags, _ := rootCmd.PersistentFlags().GetString("output")
+			writer := withStore(newWriter(outputFlags), []platforms.Platform{p}, opts)
+
+			setupProxy()
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			printResults(ctx, platforms.StreamAllProgramsScope(ctx, p, creds, opts, concurrency), writer)
+		},
+	}
+
+	for _, field := range p.AuthFields() {
+		platformCmd.Flags().StringP(field.Name, field.Shorthand, "", field.Usage)
+		if field.ConfigKey != "" {
+			viper.BindPFlag(field.ConfigKey, platformCmd.Flags().Lookup(field.Name))
+		}
+	}
+	platformCmd.Flags().IntP("concurrency", "", p.DefaultConcurrency(), "Concurrency of HTTP requests sent for fetching data")
+
+	return platformCmd
+}
+
+// newWriter returns the writer for the output format selected on the command line
+func newWriter(outputFlags string) output.Writer {
+	format, _ := rootCmd.PersistentFlags().GetString("format")
+	delimiterCharacter, _ := rootCmd.PersistentFlags().GetString("delimiter")
+	outOfScope, _ := rootCmd.PersistentFlags().GetBool("oos")
+	templateText, _ := rootCmd.PersistentFlags().GetString("template")
+	templateFile, _ := rootCmd.PersistentFlags().GetString("template-file")
+	outputDir, _ := rootCmd.PersistentFlags().GetString("output-dir")
+	zapDir, _ := rootCmd.PersistentFlags().GetString("zap-dir")
+	sqlitePath, _ := rootCmd.PersistentFlags().GetString("sqlite")
+
+	opts := output.Options{
+		OutputFlags: outputFlags,
+		Delimiter:   delimiterCharacter,
+		OutOfScope:  outOfScope,
+		Dir:         outputDir,
+	}
+
+	for _, dir := range []string{outputDir, zapDir} {
+		if dir != "" {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+
+	if templateFile != "" {
+		if templateText != "" {
+			log.Fatal("Both a template and a template file provided")
+		}
+		data, err := os.ReadFile(templateFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		templateText = string(data)
+	}
+
+	var writer output.Writer
+	var err error
+	if templateText != "" {
+		if rootCmd.PersistentFlags().Changed("format") {
+			log.Fatal("Templates can't be combined with --format")
+		}
+		writer, err = output.NewTemplateWriter(os.Stdout, templateText, opts)
+	} else {
+		writer, err = output.New(format, os.Stdout, opts)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if zapDir != "" {
+		writer = output.Multi(writer, output.NewZAPWriter(zapDir))
+	}
+
+	if sqlitePath != "" {
+		categoriesFlag, _ := rootCmd.PersistentFlags().GetString("categories")
+		categories, _ := scope.ParseCategories(categoriesFlag)
+		// Targets dropped by -b are still listed, so they must not be removed as stale
+		bbpOnly, _ := rootCmd.PersistentFlags().GetBool("bbpOnly")
+		db, err := sqlite.Open(sqlitePath, categories, !bbpOnly)
+		if err != nil {
+			log.Fatal(err)
+		}
+		writer = output.Multi(writer, db)
+	}
+	return writer
+}
+
+// printResults writes every program received from results, logging the ones that
+// could not be fetched, and exits with an error if any of them failed
+func printResults(ctx context.Context, results <-chan platforms.Result, writer output.Writer) {
+	failures := 0
+	for r := range results {
+		if r.Err != nil {
+			utils.Log.Error(r.Err)
+			failures++
+			if ew, ok := writer.(output.ErrorWriter); ok {
+				if err := ew.WriteError(r.Platform, r.Handle, r.Err); err != nil {
+					utils.Log.Fatal(err)
+				}
+			}
+			continue
+		}
+		if err := writer.WriteProgram(r.Program); err != nil {
+			utils.Log.Fatal(err)
+		}
+	}
+	// Interrupted runs are left unfinished, so that they are not mistaken for complete ones
+	if ctx.Err() != nil {
+		utils.Log.Fatal(ctx.Err())
+	}
+	if err := writer.Close(); err != nil {
+		utils.Log.Fatal(err)
+	}
+	if failures > 0 {
+		utils.Log.Fatalf("bbscope run completed with %d error(s)", failures)
+	}
+	utils.Log.Info("bbscope run successfully")
+}
+
+// getCredentials reads the platform's credentials from the command line and the config file
+func getCredentials(cmd *cobra.Command, p platforms.Platform) platforms.Credentials {
+	creds := make(platforms.Credentials)
+	for _, field := range p.AuthFields() {
+		value, _ := cmd.Flags().GetString(field.Name)
+		if field.ConfigKey != "" {
+			value = viper.GetViper().GetString(field.ConfigKey)
+		}
+
+		if field.Required && value == "" {
+			log.Fatalf("Please provide your %s %s (--%s flag)", p.DisplayName(), field.Name, field.Name)
+		}
+		creds[field.Name] = value
+	}
+	return creds
+}
+
+// getOptions reads the filters shared by every platform
+func getOptions(cmd *cobra.Command) platforms.Options {
+	var opts platforms.Options
+	opts.Categories, _ = rootCmd.Flags().GetString("categories")
+	opts.BbpOnly, _ = rootCmd.Flags().GetBool("bbpOnly")
+	opts.PvtOnly, _ = rootCmd.Flags().GetBool("pvtOnly")
+	opts.PublicOnly, _ = rootCmd.Flags().GetBool("public-only")
+	opts.ActiveOnly, _ = rootCmd.Flags().GetBool("active-only")
+
+	if opts.PvtOnly && opts.PublicOnly {
+		log.Fatal("Both public programs only and privates only flag true")
+	}
+
+	if _, err := scope.ParseCategories(opts.Categories); err != nil {
+		log.Fatal(err)
+	}
+	return opts
+}
+
+func setupProxy() {
+	proxy, _ := rootCmd.PersistentFlags().GetString("proxy")
+	if proxy != "" {
+		proxyURL, err := url.Parse(proxy)
+		if err != nil {
+			log.Fatal("Invalid Proxy String")
+		}
+		http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		http.DefaultTransport.(*http.Transport).Proxy = http.ProxyURL(proxyURL)
+	}
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/sw33tLie/bbscope/internal/utils"
+	"github.com/sw33tLie/bbscope/pkg/platforms"
+	"github.com/sw33tLie/bbscope/pkg/report"
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Write an HTML and Markdown report of your programs",
+	Long:  "Fetches the programs of every platform whose credentials are set in the config file and writes a report with a section per program, as a self-contained HTML page and as Markdown",
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := getOptions(cmd)
+		htmlPath, _ := cmd.Flags().GetString("html")
+		markdownPath, _ := cmd.Flags().GetString("markdown")
+		platformNames, _ := cmd.Flags().GetString("platforms")
+
+		if htmlPath == "" && markdownPath == "" {
+			log.Fatal("Nothing to write, please provide an HTML or Markdown output path")
+		}
+
+		ps := parsePlatforms(platformNames)
+
+		setupProxy()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		ps = configuredPlatforms(ps)
+		writer := withStore(&reportWriter{htmlPath: htmlPath, markdownPath: markdownPath}, ps, opts)
+		printResults(ctx, streamPlatforms(ctx, ps, opts), writer)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	reportCmd.Flags().StringP("html", "", "bbscope-report.html", "HTML report path, empty to skip it")
+	reportCmd.Flags().StringP("markdown", "", "bbscope-report.md", "Markdown report path, empty to skip it")
+	reportCmd.Flags().StringP("platforms", "", "", "Comma separated platforms to include (default: every configured platform)")
+}
+
+// parsePlatforms returns the platforms in a comma separated list of names, or all of them if names is empty
+func parsePlatforms(names string) []platforms.Platform {
+	if names == "" {
+		return platforms.All()
+	}
+
+	var ps []platforms.Platform
+	for _, name := range strings.Split(names, ",") {
+		p, ok := platforms.Get(strings.TrimSpace(name))
+		if !ok {
+			log.Fatalf("Unknown platform %q", name)
+		}
+		ps = append(ps, p)
+	}
+	return ps
+}
+
+// reportWriter collects every program and writes the reports once all have been fetched
+type reportWriter struct {
+	htmlPath     string
+	markdownPath string
+	programs     []scope.ProgramData
+}
+
+func (r *reportWriter) WriteProgram(pData scope.ProgramData) error {
+	r.programs = append(r.programs, pData)
+	return nil
+}
+
+func (r *reportWriter) Close() error {
+	rep := report.New(r.programs, time.Now())
+
+	for _, file := range []struct {
+		path  string
+		write func(w io.Writer, r report.Report) error
+	}{
+		{r.htmlPath, report.WriteHTML},
+		{r.markdownPath, report.WriteMarkdown},
+	} {
+		if file.path == "" {
+			continue
+		}
+
+		f, err := os.Create(file.path)
+		if err != nil {
+			return err
+		}
+		err = file.write(f, rep)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		utils.Log.Info("Report written to ", file.path)
+	}
+	return nil
+}
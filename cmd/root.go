@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/sw33tLie/bbscope/internal/utils"
+	"github.com/sw33tLie/bbscope/pkg/output"
+	"github.com/sw33tLie/bbscope/pkg/scope"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
@@ -18,8 +21,8 @@ var cfgFile string
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "bbscope",
-	Short: "Grab scope from HackerOne, Bugcrowd, Intigriti and YesWeHack",
-	Long:  `The ultimate scope gathering tool for HackerOne, Bugcrowd, Intigriti and YesWeHack by sw33tLie`,
+	Short: "Grab scope from HackerOne, Bugcrowd, Intigriti, YesWeHack and Immunefi",
+	Long:  `The ultimate scope gathering tool for HackerOne, Bugcrowd, Intigriti, YesWeHack and Immunefi by sw33tLie`,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -37,10 +40,23 @@ func init() {
 
 	// Global flags
 	rootCmd.PersistentFlags().StringP("proxy", "", "", "HTTP Proxy (Useful for debugging. Example: http://127.0.0.1:8080)")
-	rootCmd.PersistentFlags().StringP("output", "o", "t", "Output flags. Supported: t (target), d (target description), c (category), u (program URL). Can be combined. Example: -o tdu")
+	rootCmd.PersistentFlags().StringP("output", "o", "t", "Output flags. Supported: t (target), d (target description), c (category), r (platform category), b (bounty eligible), s (max severity), i (asset ID), f (asset creation date), m (asset last update), o (target source: identifier or description), h (contract chain), a (contract address), u (program URL), P (platform), H (program handle), N (program name), V (private/public), B (program offers bounties), L (min bounty), M (max bounty), C (bounty currency), S (program state), A (submission state), D (launch date). Can be combined. Example: -o tdu")
+	rootCmd.PersistentFlags().StringP("format", "", "text", "Output format (Available: "+strings.Join(output.Formats(), ", ")+"). json prints whole programs, jsonl one target per line, csv and tsv the columns selected with -o, burp Burp Suite project options with the target scope, urls deduplicated URLs of web targets, ipranges merged IP ranges as CIDRs, apps and apps-csv deduplicated mobile apps, repos and repos-json source code repositories, contracts smart contracts grouped by chain")
+	rootCmd.PersistentFlags().StringP("output-dir", "", "", "Write the output to files in this directory: one per program for burp, include and exclude lists for ipranges")
+	rootCmd.PersistentFlags().StringP("sqlite", "", "", "Also save programs and targets to this SQLite database, updating it on repeated runs")
+	rootCmd.PersistentFlags().StringP("zap-dir", "", "", "Also write an OWASP ZAP context file per program in this directory")
+	rootCmd.PersistentFlags().StringP("template", "", "", "Go text/template rendered for each scope element, e.g. 'https://{{.Target}} # {{.Program.Name}}'. Templates named header and footer, if defined, are rendered for each program")
+	rootCmd.PersistentFlags().StringP("template-file", "", "", "File containing the template to render for each scope element")
+	rootCmd.PersistentFlags().BoolP("oos", "", false, "Print out-of-scope elements instead of in-scope ones")
 	rootCmd.PersistentFlags().StringP("delimiter", "d", " ", "Delimiter character used when printing multiple data using the output flag")
+	rootCmd.PersistentFlags().StringP("categories", "c", "all", "Scope categories, comma separated (Available: "+strings.Join(scope.CategoryNames(), ", ")+"). See the categories command for what they match on each platform")
 	rootCmd.PersistentFlags().BoolP("bbpOnly", "b", false, "Only fetch programs offering monetary rewards")
 	rootCmd.PersistentFlags().BoolP("pvtOnly", "p", false, "Only fetch data from private programs")
+	rootCmd.PersistentFlags().BoolP("public-only", "", false, "Only fetch data from public programs (HackerOne only)")
+	rootCmd.PersistentFlags().BoolP("active-only", "a", false, "Only fetch data from programs accepting submissions (HackerOne only)")
+	rootCmd.PersistentFlags().StringP("store-dir", "", "", "Directory of the local store every run is saved to (default is $HOME/.bbscope/store)")
+	rootCmd.PersistentFlags().BoolP("no-store", "", false, "Don't save this run to the local store")
+	viper.BindPFlag("store-dir", rootCmd.PersistentFlags().Lookup("store-dir"))
 	rootCmd.PersistentFlags().StringP("loglevel", "l", "info", "Set log level. Available: debug, info, warn, error, fatal")
 
 }
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/sw33tLie/bbscope/internal/utils"
+	"github.com/sw33tLie/bbscope/pkg/output"
+	"github.com/sw33tLie/bbscope/pkg/platforms"
+	"github.com/sw33tLie/bbscope/pkg/scope"
+	"github.com/sw33tLie/bbscope/pkg/store"
+)
+
+// storeCmd represents the store command
+var storeCmd = &cobra.Command{
+	Use:   "store",
+	Short: "Query the programs saved by previous runs",
+	Long:  "Every run saves the programs it fetches to a local store (~/.bbscope/store by default). These commands read it back.",
+}
+
+var storeRunsCmd = &cobra.Command{
+	Use:   "runs",
+	Short: "List the runs in the store",
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := openStore()
+		if err != nil {
+			log.Fatal(err)
+		}
+		runs, err := s.Runs()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "RUN\tPLATFORMS\tPROGRAMS\tFAILED\tFINISHED")
+		for _, run := range runs {
+			failed := 0
+			for _, handles := range run.Failed {
+				failed += len(handles)
+			}
+			finished := "interrupted"
+			if run.Finished() {
+				finished = run.FinishedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%s\t%v\t%d\t%d\t%s\n", run.ID, run.Platforms, run.Programs, failed, finished)
+		}
+		w.Flush()
+	},
+}
+
+var storeShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the programs saved by a run",
+	Long:  "Prints the programs saved by a run (the latest one by default) like a fresh fetch would, so every output flag, format and filter can be used",
+	Run: func(cmd *cobra.Command, args []string) {
+		runID, _ := cmd.Flags().GetString("run")
+		platform, _ := cmd.Flags().GetString("platform")
+		handle, _ := cmd.Flags().GetString("handle")
+		outputFlags, _ := rootCmd.PersistentFlags().GetString("output")
+		opts := getOptions(cmd)
+		categories, _ := scope.ParseCategories(opts.Categories)
+
+		s, err := openStore()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if runID == "" {
+			run, err := s.LatestRun(platform)
+			if err != nil {
+				log.Fatal(err)
+			}
+			runID = run.ID
+		}
+
+		programs, err := s.Programs(runID, platform)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		writer := newWriter(outputFlags)
+		for _, pData := range programs {
+			if handle != "" && pData.Handle != handle {
+				continue
+			}
+			pData, ok := filterProgram(pData, opts, categories)
+			if !ok {
+				continue
+			}
+			if err := writer.WriteProgram(pData); err != nil {
+				log.Fatal(err)
+			}
+		}
+		if err := writer.Close(); err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(storeCmd)
+	storeCmd.AddCommand(storeRunsCmd)
+	storeCmd.AddCommand(storeShowCmd)
+
+	storeShowCmd.Flags().StringP("run", "", "", "Run ID, as listed by the runs command (default: the latest run)")
+	storeShowCmd.Flags().StringP("platform", "", "", "Only print programs from this platform")
+	storeShowCmd.Flags().StringP("handle", "", "", "Only print the program with this handle")
+}
+
+// filterProgram applies the program and target filters of a live fetch to a saved program,
+// and returns false if the program itself is filtered out
+func filterProgram(pData scope.ProgramData, opts platforms.Options, categories []string) (scope.ProgramData, bool) {
+	if (opts.BbpOnly && !pData.OffersBounties) || (opts.PvtOnly && !pData.Private) || (opts.PublicOnly && pData.Private) {
+		return pData, false
+	}
+
+	pData.InScope = scope.FilterCategories(pData.InScope, categories)
+	pData.OutOfScope = scope.FilterCategories(pData.OutOfScope, categories)
+
+	if opts.BbpOnly {
+		var eligible []scope.ScopeElement
+		for _, element := range pData.InScope {
+			if element.BountyEligible == nil || *element.BountyEligible {
+				eligible = append(eligible, element)
+			}
+		}
+		pData.InScope = eligible
+	}
+	return pData, true
+}
+
+// openStore opens the store selected on the command line or in the config file
+func openStore() (*store.Store, error) {
+	dir := viper.GetString("store-dir")
+	if dir == "" {
+		var err error
+		dir, err = store.DefaultDir()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return store.Open(dir)
+}
+
+// withStore makes writer also save the fetched programs as a new run in the store, unless disabled
+func withStore(writer output.Writer, ps []platforms.Platform, opts platforms.Options) output.Writer {
+	run, err := newStoreRun(ps, opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if run == nil {
+		return writer
+	}
+	return output.Multi(writer, run)
+}
+
+// newStoreRun starts a new run in the store, or returns nil if runs are not saved
+func newStoreRun(ps []platforms.Platform, opts platforms.Options) (*store.RunWriter, error) {
+	if noStore, _ := rootCmd.PersistentFlags().GetBool("no-store"); noStore {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(ps))
+	for _, p := range ps {
+		names = append(names, p.Name())
+	}
+
+	s, err := openStore()
+	if err != nil {
+		return nil, err
+	}
+	run, err := s.NewRun(names, opts)
+	if err != nil {
+		return nil, err
+	}
+	utils.Log.Debug("Saving run " + run.Run().ID + " to the store, " + strconv.Itoa(len(names)) + " platform(s)")
+	return run, nil
+}
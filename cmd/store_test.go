@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sw33tLie/bbscope/pkg/platforms"
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+func TestFilterProgram(t *testing.T) {
+	program := scope.ProgramData{
+		Handle:         "program",
+		OffersBounties: true,
+		InScope: []scope.ScopeElement{
+			{Target: "*.example.com", Category: scope.CategoryWildcard, BountyEligible: scope.Bool(true)},
+			{Target: "example.com", Category: scope.CategoryURL, BountyEligible: scope.Bool(false)},
+			{Target: "10.0.0.0/24", Category: scope.CategoryCIDR},
+		},
+		OutOfScope: []scope.ScopeElement{{Target: "blog.example.com", Category: scope.CategoryURL}},
+	}
+	vdp := scope.ProgramData{Handle: "vdp", Private: true, InScope: program.InScope}
+
+	tests := []struct {
+		name       string
+		pData      scope.ProgramData
+		opts       platforms.Options
+		categories []string
+		wantOK     bool
+		wantIn     []string
+		wantOut    []string
+	}{
+		{"no filter", program, platforms.Options{}, nil, true, []string{"*.example.com", "example.com", "10.0.0.0/24"}, []string{"blog.example.com"}},
+		{"categories", program, platforms.Options{}, []string{scope.CategoryURL}, true, []string{"example.com"}, []string{"blog.example.com"}},
+		{"bounty", program, platforms.Options{BbpOnly: true}, nil, true, []string{"*.example.com", "10.0.0.0/24"}, []string{"blog.example.com"}},
+		{"bounty program", vdp, platforms.Options{BbpOnly: true}, nil, false, nil, nil},
+		{"private", program, platforms.Options{PvtOnly: true}, nil, false, nil, nil},
+		{"public", vdp, platforms.Options{PublicOnly: true}, nil, false, nil, nil},
+	}
+
+	for _, tt := range tests {
+		pData, ok := filterProgram(tt.pData, tt.opts, tt.categories)
+		if ok != tt.wantOK {
+			t.Errorf("%s: got %v, want %v", tt.name, ok, tt.wantOK)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if got := targetNames(pData.InScope); !reflect.DeepEqual(got, tt.wantIn) {
+			t.Errorf("%s: got in scope %v, want %v", tt.name, got, tt.wantIn)
+		}
+		if got := targetNames(pData.OutOfScope); !reflect.DeepEqual(got, tt.wantOut) {
+			t.Errorf("%s: got out of scope %v, want %v", tt.name, got, tt.wantOut)
+		}
+	}
+}
+
+func targetNames(elements []scope.ScopeElement) []string {
+	var result []string
+	for _, element := range elements {
+		result = append(result, element.Target)
+	}
+	return result
+}
@@ -0,0 +1,247 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/sw33tLie/bbscope/internal/utils"
+	"github.com/sw33tLie/bbscope/pkg/diff"
+	"github.com/sw33tLie/bbscope/pkg/notify"
+	"github.com/sw33tLie/bbscope/pkg/platforms"
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+// watchCmd represents the watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Keep polling platforms and print scope changes as they happen",
+	Long: `Polls every platform whose credentials are set in the config file, each on its own interval, and compares every fetch with the previous one.
+Each program change is printed as a JSON line, and sent to the webhooks configured in the config file or with --webhook. Fetch errors are logged and retried on the next poll.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := getOptions(cmd)
+		interval, _ := cmd.Flags().GetDuration("interval")
+		intervals, _ := cmd.Flags().GetStringToString("intervals")
+		jitter, _ := cmd.Flags().GetFloat64("jitter")
+		eventsFile, _ := cmd.Flags().GetString("events-file")
+		platformNames, _ := cmd.Flags().GetString("platforms")
+
+		if jitter < 0 || jitter >= 1 {
+			log.Fatal("Jitter must be between 0 and 1")
+		}
+
+		ps := configuredPlatforms(parsePlatforms(platformNames))
+		if len(ps) == 0 {
+			log.Fatal("No platform to watch, please set your credentials in the config file")
+		}
+
+		platformIntervals := make(map[string]time.Duration)
+		for name, value := range intervals {
+			if _, ok := platforms.Get(name); !ok {
+				log.Fatalf("Unknown platform %q", name)
+			}
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				log.Fatalf("Invalid interval for %s: %v", name, err)
+			}
+			platformIntervals[name] = d
+		}
+
+		var out io.Writer = os.Stdout
+		if eventsFile != "" {
+			f, err := os.OpenFile(eventsFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer f.Close()
+			out = f
+		}
+		notifier := newNotifier(cmd)
+
+		setupProxy()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		sink := eventSinks{&jsonLinesSink{encoder: json.NewEncoder(out)}}
+		if !notifier.Empty() {
+			sink = append(sink, &notifierSink{ctx: ctx, notifier: notifier})
+		}
+
+		wg := new(sync.WaitGroup)
+		for _, p := range ps {
+			d, ok := platformIntervals[p.Name()]
+			if !ok {
+				d = interval
+			}
+
+			wg.Add(1)
+			go func(p platforms.Platform, d time.Duration) {
+				defer wg.Done()
+				watchPlatform(ctx, p, opts, d, jitter, sink)
+			}(p, d)
+		}
+		wg.Wait()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().DurationP("interval", "", time.Hour, "Time between two fetches of a platform")
+	watchCmd.Flags().StringToStringP("intervals", "", nil, "Per platform intervals, overriding --interval (e.g. h1=30m,immunefi=6h)")
+	watchCmd.Flags().Float64P("jitter", "", 0.1, "Randomly shift each interval by up to this fraction of it, so polls don't happen like clockwork")
+	watchCmd.Flags().StringP("events-file", "", "", "Append events to this file instead of printing them")
+	watchCmd.Flags().StringP("platforms", "", "", "Comma separated platforms to watch (default: every configured platform)")
+	addWebhookFlag(watchCmd)
+}
+
+// eventSink receives the events found by watch. It may be called concurrently.
+type eventSink interface {
+	WriteEvents(events []diff.Event) error
+}
+
+// jsonLinesSink writes each event as a JSON line
+type jsonLinesSink struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+func (s *jsonLinesSink) WriteEvents(events []diff.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, event := range events {
+		if err := s.encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notifierSink sends events to webhooks
+type notifierSink struct {
+	ctx      context.Context
+	notifier *notify.Notifier
+}
+
+func (s *notifierSink) WriteEvents(events []diff.Event) error {
+	return s.notifier.Notify(s.ctx, events)
+}
+
+// eventSinks sends events to every sink, even if some of them fail
+type eventSinks []eventSink
+
+func (sinks eventSinks) WriteEvents(events []diff.Event) error {
+	var errs []error
+	for _, sink := range sinks {
+		if err := sink.WriteEvents(events); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// watchPlatform fetches a platform every interval until ctx is done, and sends the changes
+// between consecutive fetches to sink. The first fetch is compared with the latest run in
+// the store, if it was fetched with the same filters.
+func watchPlatform(ctx context.Context, p platforms.Platform, opts platforms.Options, interval time.Duration, jitter float64, sink eventSink) {
+	var previous *snapshot
+	if noStore, _ := rootCmd.PersistentFlags().GetBool("no-store"); !noStore {
+		if s, err := openStore(); err != nil {
+			utils.Log.Error(err)
+		} else if run, err := s.LatestRun(p.Name()); err == nil && run.Options == opts {
+			if snap, err := loadSnapshot(s, run.ID); err == nil {
+				snap = snap.only(p.Name())
+				previous = &snap
+				utils.Log.Infof("Comparing %s with run %s", p.DisplayName(), run.ID)
+			}
+		}
+	}
+
+	for {
+		utils.Log.Infof("Fetching %s", p.DisplayName())
+		current, err := fetchSnapshot(ctx, []platforms.Platform{p}, opts)
+		if ctx.Err() != nil {
+			return
+		}
+
+		switch {
+		case err != nil:
+			utils.Log.Errorf("Skipping %s fetch: %v", p.DisplayName(), err)
+		case current.listingFailed(p.Name()):
+			utils.Log.Warnf("Could not list %s programs, retrying in %s", p.DisplayName(), interval)
+		case previous == nil:
+			utils.Log.Infof("First %s fetch, %d program(s)", p.DisplayName(), len(current.Programs))
+			previous = &current
+		default:
+			changes := compareSnapshots(*previous, current)
+			utils.Log.Infof("%s: %d program(s) changed", p.DisplayName(), len(changes))
+			if len(changes) > 0 {
+				if err := sink.WriteEvents(diff.Events(changes, previous.ID, current.ID, time.Now().UTC())); err != nil {
+					utils.Log.Error(err)
+				}
+			}
+			current = current.carryOver(*previous)
+			previous = &current
+		}
+
+		d := time.Duration(float64(interval) * (1 + jitter*(2*rand.Float64()-1)))
+		if err := utils.Sleep(ctx, d); err != nil {
+			return
+		}
+	}
+}
+
+// only returns the part of the snapshot about platform
+func (s snapshot) only(platform string) snapshot {
+	filtered := snapshot{ID: s.ID, Platforms: []string{platform}, Options: s.Options, Failed: make(map[string][]string)}
+	if handles, ok := s.Failed[platform]; ok {
+		filtered.Failed[platform] = handles
+	}
+	for _, pData := range s.Programs {
+		if pData.Platform == platform {
+			filtered.Programs = append(filtered.Programs, pData)
+		}
+	}
+	return filtered
+}
+
+// listingFailed reports whether the platform's programs could not be listed at all
+func (s snapshot) listingFailed(platform string) bool {
+	for _, handle := range s.Failed[platform] {
+		if handle == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// carryOver fills the programs that could not be fetched with their previous data, so that they
+// are compared with it the next time they are fetched, instead of being reported as new
+func (s snapshot) carryOver(previous snapshot) snapshot {
+	previousPrograms := make(map[string]scope.ProgramData)
+	for _, pData := range previous.Programs {
+		previousPrograms[pData.Platform+"|"+pData.Handle] = pData
+	}
+
+	failed := make(map[string][]string)
+	for platform, handles := range s.Failed {
+		for _, handle := range handles {
+			if pData, ok := previousPrograms[platform+"|"+handle]; ok {
+				s.Programs = append(s.Programs, pData)
+			} else {
+				failed[platform] = append(failed[platform], handle)
+			}
+		}
+	}
+	s.Failed = failed
+	return s
+}
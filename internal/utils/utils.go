@@ -1,7 +1,9 @@
 package utils
 
 import (
+	"context"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	log "github.com/sirupsen/logrus"
@@ -19,6 +21,19 @@ func AreSlicesEqual(a, b []string) bool {
 	return true
 }
 
+// Sleep waits for d, returning early with the context's error if ctx is done first
+func Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 var Log = logrus.New()
 
 func SetLogLevel(level string) {
@@ -0,0 +1,78 @@
+package workerpool
+
+import (
+	"context"
+	"sync"
+)
+
+type result[R any] struct {
+	index int
+	value R
+	err   error
+}
+
+// Stream calls fn on every item using at most concurrency goroutines, and passes
+// each result to emit in the same order as items. emit is never called
+// concurrently. Stream returns once every item has been emitted, ctx is done or
+// emit returns false.
+func Stream[T, R any](ctx context.Context, items []T, concurrency int, fn func(ctx context.Context, item T) (R, error), emit func(item T, value R, err error) bool) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int)
+	results := make(chan result[R])
+	workers := new(sync.WaitGroup)
+	workers.Add(concurrency)
+
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer workers.Done()
+			for index := range jobs {
+				value, err := fn(ctx, items[index])
+				select {
+				case results <- result[R]{index: index, value: value, err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for index := range items {
+			select {
+			case jobs <- index:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	// Results arrive in completion order: hold them back until all previous items have been emitted
+	pending := make(map[int]result[R])
+	next := 0
+	for r := range results {
+		pending[r.index] = r
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			if !emit(items[r.index], r.value, r.err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errOdd = errors.New("odd")
+
+func TestStream(t *testing.T) {
+	tests := []struct {
+		name        string
+		items       int
+		concurrency int
+		// stopAfter makes emit return false after that many items, 0 never stops
+		stopAfter int
+		// cancelAfter cancels the context after that many items, 0 never cancels
+		cancelAfter int
+		wantEmitted int
+	}{
+		{name: "empty", items: 0, concurrency: 4, wantEmitted: 0},
+		{name: "sequential", items: 20, concurrency: 1, wantEmitted: 20},
+		{name: "concurrent", items: 100, concurrency: 8, wantEmitted: 100},
+		{name: "more workers than items", items: 3, concurrency: 10, wantEmitted: 3},
+		{name: "zero concurrency", items: 5, concurrency: 0, wantEmitted: 5},
+		{name: "early stop", items: 100, concurrency: 8, stopAfter: 10, wantEmitted: 10},
+		{name: "early stop on first", items: 50, concurrency: 4, stopAfter: 1, wantEmitted: 1},
+		{name: "cancel", items: 100, concurrency: 8, cancelAfter: 10, wantEmitted: 10},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			items := make([]int, tt.items)
+			for i := range items {
+				items[i] = i
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var running, maxRunning int32
+			fn := func(ctx context.Context, item int) (int, error) {
+				n := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+				for {
+					max := atomic.LoadInt32(&maxRunning)
+					if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+						break
+					}
+				}
+
+				time.Sleep(time.Duration(rand.Intn(2000)) * time.Microsecond)
+				if item%2 == 1 {
+					return item * 10, errOdd
+				}
+				return item * 10, nil
+			}
+
+			var emitted []int
+			emit := func(item int, value int, err error) bool {
+				if value != item*10 {
+					t.Errorf("item %d: got value %d", item, value)
+				}
+				if (item%2 == 1) != errors.Is(err, errOdd) {
+					t.Errorf("item %d: got error %v", item, err)
+				}
+				emitted = append(emitted, item)
+
+				if tt.cancelAfter > 0 && len(emitted) == tt.cancelAfter {
+					cancel()
+					// Let workers notice the cancellation before the next result is received
+					time.Sleep(10 * time.Millisecond)
+				}
+				return tt.stopAfter == 0 || len(emitted) < tt.stopAfter
+			}
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				Stream(ctx, items, tt.concurrency, fn, emit)
+			}()
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Stream did not return")
+			}
+
+			if tt.cancelAfter > 0 {
+				// Results already received when ctx was canceled may still be emitted
+				if len(emitted) < tt.wantEmitted || len(emitted) > tt.wantEmitted+tt.concurrency {
+					t.Errorf("emitted %d items, want about %d", len(emitted), tt.wantEmitted)
+				}
+			} else if len(emitted) != tt.wantEmitted {
+				t.Errorf("emitted %d items, want %d", len(emitted), tt.wantEmitted)
+			}
+			for i, item := range emitted {
+				if item != i {
+					t.Fatalf("emitted out of order: %v", emitted)
+				}
+			}
+
+			concurrency := tt.concurrency
+			if concurrency < 1 {
+				concurrency = 1
+			}
+			if max := atomic.LoadInt32(&maxRunning); int(max) > concurrency {
+				t.Errorf("%d calls ran at once, limit is %d", max, concurrency)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package contracts
+
+import (
+	"encoding/hex"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+	"golang.org/x/crypto/sha3"
+)
+
+// Chain is an EVM chain, as identified by its block explorer
+type Chain struct {
+	ID   int64
+	Name string
+}
+
+// Explorers maps block explorer hosts to the chain they index
+var Explorers = map[string]Chain{
+	"etherscan.io":                {1, "ethereum"},
+	"goerli.etherscan.io":         {5, "goerli"},
+	"sepolia.etherscan.io":        {11155111, "sepolia"},
+	"optimistic.etherscan.io":     {10, "optimism"},
+	"bscscan.com":                 {56, "bsc"},
+	"testnet.bscscan.com":         {97, "bsc-testnet"},
+	"polygonscan.com":             {137, "polygon"},
+	"zkevm.polygonscan.com":       {1101, "polygon-zkevm"},
+	"arbiscan.io":                 {42161, "arbitrum"},
+	"nova.arbiscan.io":            {42170, "arbitrum-nova"},
+	"snowtrace.io":                {43114, "avalanche"},
+	"snowscan.xyz":                {43114, "avalanche"},
+	"ftmscan.com":                 {250, "fantom"},
+	"gnosisscan.io":               {100, "gnosis"},
+	"basescan.org":                {8453, "base"},
+	"lineascan.build":             {59144, "linea"},
+	"celoscan.io":                 {42220, "celo"},
+	"moonscan.io":                 {1284, "moonbeam"},
+	"moonriver.moonscan.io":       {1285, "moonriver"},
+	"cronoscan.com":               {25, "cronos"},
+	"era.zksync.network":          {324, "zksync"},
+	"explorer.zksync.io":          {324, "zksync"},
+	"scrollscan.com":              {534352, "scroll"},
+	"blastscan.io":                {81457, "blast"},
+	"mantlescan.xyz":              {5000, "mantle"},
+	"explorer.mantle.xyz":         {5000, "mantle"},
+	"aurorascan.dev":              {1313161554, "aurora"},
+	"explorer.aurora.dev":         {1313161554, "aurora"},
+	"gnosis.blockscout.com":       {100, "gnosis"},
+	"explorer.metis.io":           {1088, "metis"},
+	"andromeda-explorer.metis.io": {1088, "metis"},
+}
+
+var addressRegex = regexp.MustCompile(`\b0x[0-9a-fA-F]{40}\b`)
+
+// Parse reads the chain and the address of a contract out of its block explorer URL
+// (e.g. "https://etherscan.io/address/0x..."). It reports false for unknown explorers
+// and for URLs without an address.
+func Parse(explorerURL string) (*scope.Contract, bool) {
+	explorerURL = strings.TrimSpace(explorerURL)
+	u, err := url.Parse(explorerURL)
+	if err != nil {
+		return nil, false
+	}
+
+	chain, ok := Explorers[strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")]
+	if !ok {
+		return nil, false
+	}
+
+	address := addressRegex.FindString(u.Path + "#" + u.Fragment)
+	if address == "" {
+		return nil, false
+	}
+
+	return &scope.Contract{
+		ChainID:     chain.ID,
+		Chain:       chain.Name,
+		Address:     ChecksumAddress(address),
+		ExplorerURL: explorerURL,
+	}, true
+}
+
+// ChecksumAddress returns the EIP-55 mixed case form of a hex address
+func ChecksumAddress(address string) string {
+	lower := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(address, "0x"), "0X"))
+
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write([]byte(lower))
+	digest := hex.EncodeToString(hash.Sum(nil))
+
+	checksummed := []byte(lower)
+	for i, c := range checksummed {
+		if c >= 'a' && c <= 'f' && digest[i] >= '8' {
+			checksummed[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(checksummed)
+}
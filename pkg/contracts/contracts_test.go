@@ -0,0 +1,93 @@
+package contracts
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test vectors from EIP-55
+var eip55Vectors = []string{
+	// All caps
+	"0x52908400098527886E0F7030069857D2E4169EE7",
+	"0x8617E340B3D01FA5F11F306F4090FD50E238070D",
+	// All lower
+	"0xde709f2102306220921060314715629080e2fb77",
+	"0x27b1fdb04752bbc536007a920d24acb045561c26",
+	// Normal
+	"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+	"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+	"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+	"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+}
+
+func TestChecksumAddress(t *testing.T) {
+	for _, want := range eip55Vectors {
+		hexPart := strings.TrimPrefix(want, "0x")
+		for _, input := range []string{
+			want,
+			"0x" + strings.ToLower(hexPart),
+			"0x" + strings.ToUpper(hexPart),
+			"0X" + strings.ToUpper(hexPart),
+		} {
+			if got := ChecksumAddress(input); got != want {
+				t.Errorf("ChecksumAddress(%q) = %q, want %q", input, got, want)
+			}
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	const (
+		lower   = "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"
+		checked = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	)
+
+	tests := []struct {
+		url         string
+		wantOK      bool
+		wantChainID int64
+		wantChain   string
+	}{
+		{"https://etherscan.io/address/" + lower, true, 1, "ethereum"},
+		{"https://etherscan.io/address/" + lower + "#code", true, 1, "ethereum"},
+		{"https://etherscan.io/address/" + lower + "?tab=contract#readContract", true, 1, "ethereum"},
+		{"https://www.etherscan.io/address/" + lower, true, 1, "ethereum"},
+		{"https://Etherscan.io/address/" + lower, true, 1, "ethereum"},
+		{"  https://etherscan.io/address/" + lower + "  ", true, 1, "ethereum"},
+		{"https://bscscan.com/token/" + lower, true, 56, "bsc"},
+		{"https://polygonscan.com/token/" + lower + "#balances", true, 137, "polygon"},
+		{"https://arbiscan.io/address/" + strings.ToUpper(lower[2:]), false, 0, ""},
+		{"https://arbiscan.io/address/0x" + strings.ToUpper(lower[2:]), true, 42161, "arbitrum"},
+		{"https://optimistic.etherscan.io/address/" + lower, true, 10, "optimism"},
+		{"https://gnosis.blockscout.com/address/" + lower, true, 100, "gnosis"},
+		{"https://etherscan.io/#/address/" + lower, true, 1, "ethereum"},
+		{"https://example.com/address/" + lower, false, 0, ""},
+		{"https://etherscan.io.example.com/address/" + lower, false, 0, ""},
+		{"https://etherscan.io/tokens", false, 0, ""},
+		{"https://etherscan.io/address/" + lower[:41], false, 0, ""},
+		{"https://etherscan.io/address/" + lower + "a", false, 0, ""},
+		{"https://etherscan.io/tx/0x" + strings.Repeat("a", 64), false, 0, ""},
+		{lower, false, 0, ""},
+		{"", false, 0, ""},
+	}
+
+	for _, tt := range tests {
+		contract, ok := Parse(tt.url)
+		if ok != tt.wantOK {
+			t.Errorf("Parse(%q) ok = %v, want %v", tt.url, ok, tt.wantOK)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if contract.ChainID != tt.wantChainID || contract.Chain != tt.wantChain {
+			t.Errorf("Parse(%q) chain = %d %q, want %d %q", tt.url, contract.ChainID, contract.Chain, tt.wantChainID, tt.wantChain)
+		}
+		if contract.Address != checked {
+			t.Errorf("Parse(%q) address = %q, want %q", tt.url, contract.Address, checked)
+		}
+		if contract.ExplorerURL != strings.TrimSpace(tt.url) {
+			t.Errorf("Parse(%q) explorer URL = %q", tt.url, contract.ExplorerURL)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package diff
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+// Program change statuses
+const (
+	STATUS_ADDED   = "added"
+	STATUS_REMOVED = "removed"
+	STATUS_CHANGED = "changed"
+)
+
+// FieldChange is a field whose value changed between two snapshots
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Target is a scope element, along with the list it belongs to
+type Target struct {
+	scope.ScopeElement
+	InScope bool `json:"in_scope"`
+}
+
+// TargetChange is a target listed in both snapshots whose details changed
+type TargetChange struct {
+	Target string `json:"target"`
+	// Category is the target's category in the newer snapshot
+	Category string        `json:"category"`
+	InScope  bool          `json:"in_scope"`
+	Changes  []FieldChange `json:"changes"`
+}
+
+// ProgramChange lists what changed in a program between two snapshots
+type ProgramChange struct {
+	Platform string `json:"platform"`
+	Handle   string `json:"handle"`
+	Name     string `json:"name"`
+	Url      string `json:"url"`
+	// Private and OffersBounties come from the newer snapshot the program is in
+	Private        bool `json:"private"`
+	OffersBounties bool `json:"offers_bounties"`
+	// Status is one of the STATUS_* constants
+	Status string `json:"status"`
+	// Changes lists the program-level fields that changed
+	Changes        []FieldChange  `json:"changes,omitempty"`
+	AddedTargets   []Target       `json:"added_targets,omitempty"`
+	RemovedTargets []Target       `json:"removed_targets,omitempty"`
+	ChangedTargets []TargetChange `json:"changed_targets,omitempty"`
+}
+
+// Compare returns the changes between two snapshots of the same programs, sorted by platform
+// and handle. Programs only in new are added, with all their targets, and programs only in old
+// are removed.
+func Compare(old []scope.ProgramData, new []scope.ProgramData) []ProgramChange {
+	oldPrograms := make(map[string]scope.ProgramData)
+	for _, pData := range old {
+		oldPrograms[programKey(pData)] = pData
+	}
+	newPrograms := make(map[string]bool)
+
+	var changes []ProgramChange
+	for _, pData := range new {
+		newPrograms[programKey(pData)] = true
+
+		oldData, ok := oldPrograms[programKey(pData)]
+		if !ok {
+			change := newProgramChange(pData, STATUS_ADDED)
+			change.AddedTargets = targets(pData)
+			changes = append(changes, change)
+			continue
+		}
+
+		if change, ok := compareProgram(oldData, pData); ok {
+			changes = append(changes, change)
+		}
+	}
+
+	for _, pData := range old {
+		if !newPrograms[programKey(pData)] {
+			change := newProgramChange(pData, STATUS_REMOVED)
+			change.RemovedTargets = targets(pData)
+			changes = append(changes, change)
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Platform != changes[j].Platform {
+			return changes[i].Platform < changes[j].Platform
+		}
+		return changes[i].Handle < changes[j].Handle
+	})
+	return changes
+}
+
+func programKey(pData scope.ProgramData) string {
+	return pData.Platform + "|" + pData.Handle
+}
+
+func newProgramChange(pData scope.ProgramData, status string) ProgramChange {
+	return ProgramChange{
+		Platform:       pData.Platform,
+		Handle:         pData.Handle,
+		Name:           pData.Name,
+		Url:            pData.Url,
+		Private:        pData.Private,
+		OffersBounties: pData.OffersBounties,
+		Status:         status,
+	}
+}
+
+func targets(pData scope.ProgramData) []Target {
+	var all []Target
+	for _, element := range pData.InScope {
+		all = append(all, Target{ScopeElement: element, InScope: true})
+	}
+	for _, element := range pData.OutOfScope {
+		all = append(all, Target{ScopeElement: element, InScope: false})
+	}
+	return all
+}
+
+// compareProgram reports the changes between two snapshots of a program, and false if there are none
+func compareProgram(old scope.ProgramData, new scope.ProgramData) (ProgramChange, bool) {
+	change := newProgramChange(new, STATUS_CHANGED)
+
+	change.Changes = compareFields([]FieldChange{
+		{"offers_bounties", strconv.FormatBool(old.OffersBounties), strconv.FormatBool(new.OffersBounties)},
+		{"min_bounty", formatFloat(old.MinBounty), formatFloat(new.MinBounty)},
+		{"max_bounty", formatFloat(old.MaxBounty), formatFloat(new.MaxBounty)},
+		{"state", old.State, new.State},
+		{"submission_state", old.SubmissionState, new.SubmissionState},
+	})
+
+	for _, list := range []struct {
+		old     []scope.ScopeElement
+		new     []scope.ScopeElement
+		inScope bool
+	}{
+		{old.InScope, new.InScope, true},
+		{old.OutOfScope, new.OutOfScope, false},
+	} {
+		added, removed, changed := compareElements(list.old, list.new, list.inScope)
+		change.AddedTargets = append(change.AddedTargets, added...)
+		change.RemovedTargets = append(change.RemovedTargets, removed...)
+		change.ChangedTargets = append(change.ChangedTargets, changed...)
+	}
+
+	ok := len(change.Changes) > 0 || len(change.AddedTargets) > 0 || len(change.RemovedTargets) > 0 || len(change.ChangedTargets) > 0
+	return change, ok
+}
+
+// compareElements matches elements by target. When a program lists a target twice, only the
+// first one is compared.
+func compareElements(old []scope.ScopeElement, new []scope.ScopeElement, inScope bool) (added []Target, removed []Target, changed []TargetChange) {
+	oldElements := make(map[string]scope.ScopeElement)
+	for _, element := range old {
+		if _, ok := oldElements[element.Target]; !ok {
+			oldElements[element.Target] = element
+		}
+	}
+	newElements := make(map[string]bool)
+
+	for _, element := range new {
+		if newElements[element.Target] {
+			continue
+		}
+		newElements[element.Target] = true
+
+		oldElement, ok := oldElements[element.Target]
+		if !ok {
+			added = append(added, Target{ScopeElement: element, InScope: inScope})
+			continue
+		}
+
+		fields := compareFields([]FieldChange{
+			{"description", oldElement.Description, element.Description},
+			{"bounty_eligible", oldElement.BountyEligibility(), element.BountyEligibility()},
+			{"category", oldElement.Category, element.Category},
+		})
+		if len(fields) > 0 {
+			changed = append(changed, TargetChange{Target: element.Target, Category: element.Category, InScope: inScope, Changes: fields})
+		}
+	}
+
+	for _, element := range old {
+		if !newElements[element.Target] {
+			removed = append(removed, Target{ScopeElement: element, InScope: inScope})
+			// Report duplicates once
+			newElements[element.Target] = true
+		}
+	}
+	return added, removed, changed
+}
+
+// compareFields returns the fields whose value changed
+func compareFields(fields []FieldChange) []FieldChange {
+	var changed []FieldChange
+	for _, field := range fields {
+		if field.Old != field.New {
+			changed = append(changed, field)
+		}
+	}
+	return changed
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,231 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+func element(target string, category string) scope.ScopeElement {
+	return scope.ScopeElement{Target: target, Category: category, BountyEligible: scope.Bool(true)}
+}
+
+func program(handle string, inScope []scope.ScopeElement, outOfScope []scope.ScopeElement) scope.ProgramData {
+	return scope.ProgramData{
+		Platform:       "h1",
+		Handle:         handle,
+		Name:           handle,
+		Url:            "https://hackerone.com/" + handle,
+		OffersBounties: true,
+		MaxBounty:      1000,
+		InScope:        inScope,
+		OutOfScope:     outOfScope,
+	}
+}
+
+// summary is the part of a ProgramChange the tests check
+type summary struct {
+	Handle  string
+	Status  string
+	Fields  []string
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+func summarize(changes []ProgramChange) []summary {
+	var summaries []summary
+	for _, change := range changes {
+		s := summary{Handle: change.Handle, Status: change.Status}
+		for _, field := range change.Changes {
+			s.Fields = append(s.Fields, field.Field+":"+field.Old+"->"+field.New)
+		}
+		for _, target := range change.AddedTargets {
+			s.Added = append(s.Added, formatTarget(target.Target, "", target.InScope))
+		}
+		for _, target := range change.RemovedTargets {
+			s.Removed = append(s.Removed, formatTarget(target.Target, "", target.InScope))
+		}
+		for _, target := range change.ChangedTargets {
+			for _, field := range target.Changes {
+				s.Changed = append(s.Changed, formatTarget(target.Target, "", target.InScope)+" "+field.Field+":"+field.Old+"->"+field.New)
+			}
+		}
+		summaries = append(summaries, s)
+	}
+	return summaries
+}
+
+func TestCompare(t *testing.T) {
+	base := program("a", []scope.ScopeElement{element("a.com", "url"), element("*.a.com", "wildcard")}, []scope.ScopeElement{element("blog.a.com", "url")})
+
+	modified := func(f func(p *scope.ProgramData)) scope.ProgramData {
+		p := base
+		p.InScope = append([]scope.ScopeElement(nil), base.InScope...)
+		p.OutOfScope = append([]scope.ScopeElement(nil), base.OutOfScope...)
+		f(&p)
+		return p
+	}
+
+	tests := []struct {
+		name string
+		old  []scope.ProgramData
+		new  []scope.ProgramData
+		want []summary
+	}{
+		{
+			name: "unchanged",
+			old:  []scope.ProgramData{base},
+			new:  []scope.ProgramData{base},
+		},
+		{
+			name: "both empty",
+		},
+		{
+			name: "new program",
+			new:  []scope.ProgramData{base},
+			want: []summary{{Handle: "a", Status: STATUS_ADDED, Added: []string{"a.com", "*.a.com", "(out of scope) blog.a.com"}}},
+		},
+		{
+			name: "removed program",
+			old:  []scope.ProgramData{base},
+			want: []summary{{Handle: "a", Status: STATUS_REMOVED, Removed: []string{"a.com", "*.a.com", "(out of scope) blog.a.com"}}},
+		},
+		{
+			name: "added and removed targets",
+			old:  []scope.ProgramData{base},
+			new: []scope.ProgramData{modified(func(p *scope.ProgramData) {
+				p.InScope = []scope.ScopeElement{element("a.com", "url"), element("api.a.com", "api")}
+			})},
+			want: []summary{{Handle: "a", Status: STATUS_CHANGED, Added: []string{"api.a.com"}, Removed: []string{"*.a.com"}}},
+		},
+		{
+			name: "target moved out of scope",
+			old:  []scope.ProgramData{base},
+			new: []scope.ProgramData{modified(func(p *scope.ProgramData) {
+				p.InScope = p.InScope[1:]
+				p.OutOfScope = append(p.OutOfScope, element("a.com", "url"))
+			})},
+			want: []summary{{Handle: "a", Status: STATUS_CHANGED, Added: []string{"(out of scope) a.com"}, Removed: []string{"a.com"}}},
+		},
+		{
+			name: "changed target details",
+			old:  []scope.ProgramData{base},
+			new: []scope.ProgramData{modified(func(p *scope.ProgramData) {
+				p.InScope[0].Description = "main site"
+				p.InScope[0].BountyEligible = scope.Bool(false)
+				p.InScope[1].Category = "url"
+			})},
+			want: []summary{{Handle: "a", Status: STATUS_CHANGED, Changed: []string{
+				"a.com description:->main site",
+				"a.com bounty_eligible:true->false",
+				"*.a.com category:wildcard->url",
+			}}},
+		},
+		{
+			name: "eligibility becomes unknown",
+			old:  []scope.ProgramData{base},
+			new: []scope.ProgramData{modified(func(p *scope.ProgramData) {
+				p.InScope[0].BountyEligible = nil
+			})},
+			want: []summary{{Handle: "a", Status: STATUS_CHANGED, Changed: []string{"a.com bounty_eligible:true->"}}},
+		},
+		{
+			name: "program fields",
+			old:  []scope.ProgramData{base},
+			new: []scope.ProgramData{modified(func(p *scope.ProgramData) {
+				p.MaxBounty = 2500.5
+				p.State = "paused"
+			})},
+			want: []summary{{Handle: "a", Status: STATUS_CHANGED, Fields: []string{"max_bounty:1000->2500.5", "state:->paused"}}},
+		},
+		{
+			name: "ignored fields",
+			old:  []scope.ProgramData{base},
+			new: []scope.ProgramData{modified(func(p *scope.ProgramData) {
+				p.Name = "renamed"
+				p.InScope[0].MaxSeverity = "critical"
+				p.InScope[0], p.InScope[1] = p.InScope[1], p.InScope[0]
+			})},
+		},
+		{
+			name: "duplicate targets",
+			old: []scope.ProgramData{modified(func(p *scope.ProgramData) {
+				p.InScope = append(p.InScope, element("a.com", "url"))
+			})},
+			new: []scope.ProgramData{modified(func(p *scope.ProgramData) {
+				p.InScope = p.InScope[1:]
+			})},
+			want: []summary{{Handle: "a", Status: STATUS_CHANGED, Removed: []string{"a.com"}}},
+		},
+		{
+			name: "sorted by handle",
+			old:  []scope.ProgramData{program("c", nil, nil)},
+			new:  []scope.ProgramData{program("b", nil, nil), program("a", nil, nil)},
+			want: []summary{
+				{Handle: "a", Status: STATUS_ADDED},
+				{Handle: "b", Status: STATUS_ADDED},
+				{Handle: "c", Status: STATUS_REMOVED},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got := summarize(Compare(tt.old, tt.new))
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\ngot  %+v\nwant %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCompareSamePlatformHandle(t *testing.T) {
+	h1 := program("a", []scope.ScopeElement{element("a.com", "url")}, nil)
+	bc := h1
+	bc.Platform = "bc"
+
+	changes := Compare([]scope.ProgramData{h1}, []scope.ProgramData{bc})
+	if len(changes) != 2 || changes[0].Platform != "bc" || changes[0].Status != STATUS_ADDED || changes[1].Platform != "h1" || changes[1].Status != STATUS_REMOVED {
+		t.Errorf("programs with the same handle on different platforms were matched: %+v", changes)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	change := ProgramChange{
+		Handle:         "a",
+		OffersBounties: true,
+		AddedTargets:   []Target{{ScopeElement: element("*.a.com", "wildcard"), InScope: true}, {ScopeElement: element("a.apk", "android"), InScope: true}},
+		ChangedTargets: []TargetChange{{Target: "a.com", Category: "url"}},
+	}
+
+	tests := []struct {
+		name        string
+		filter      Filter
+		change      ProgramChange
+		wantOK      bool
+		wantAdded   int
+		wantChanged int
+	}{
+		{"no filter", Filter{}, change, true, 2, 1},
+		{"bounty only", Filter{BountyOnly: true}, change, true, 2, 1},
+		{"private only", Filter{PrivateOnly: true}, change, false, 0, 0},
+		{"categories", Filter{Categories: []string{"wildcard"}}, change, true, 1, 0},
+		{"no matching category", Filter{Categories: []string{"cidr"}}, change, false, 0, 0},
+		{"program fields only", Filter{Categories: []string{"url"}}, ProgramChange{Changes: []FieldChange{{"state", "", "paused"}}}, false, 0, 0},
+	}
+
+	for _, tt := range tests {
+		got, ok := tt.filter.Apply(tt.change)
+		if ok != tt.wantOK {
+			t.Errorf("%s: ok = %v, want %v", tt.name, ok, tt.wantOK)
+			continue
+		}
+		if ok && (len(got.AddedTargets) != tt.wantAdded || len(got.ChangedTargets) != tt.wantChanged) {
+			t.Errorf("%s: got %d added and %d changed targets, want %d and %d", tt.name, len(got.AddedTargets), len(got.ChangedTargets), tt.wantAdded, tt.wantChanged)
+		}
+	}
+
+	if len(change.AddedTargets) != 2 {
+		t.Error("Apply modified the original change")
+	}
+}
@@ -0,0 +1,21 @@
+package diff
+
+import "time"
+
+// Event reports a program change found when comparing two runs
+type Event struct {
+	Time time.Time `json:"time"`
+	// FromRun and ToRun are the IDs of the compared runs, empty for runs not saved to the store
+	FromRun string `json:"from_run"`
+	ToRun   string `json:"to_run"`
+	ProgramChange
+}
+
+// Events wraps changes into events
+func Events(changes []ProgramChange, fromRun string, toRun string, t time.Time) []Event {
+	events := make([]Event, 0, len(changes))
+	for _, change := range changes {
+		events = append(events, Event{Time: t, FromRun: fromRun, ToRun: toRun, ProgramChange: change})
+	}
+	return events
+}
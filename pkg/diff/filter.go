@@ -0,0 +1,64 @@
+package diff
+
+// Filter selects the program changes worth reporting
+type Filter struct {
+	BountyOnly  bool
+	PrivateOnly bool
+	// Categories keeps only the targets in these normalized categories. Nil keeps every target.
+	Categories []string
+}
+
+// Apply returns the part of change selected by the filter, and false if nothing is left.
+// With categories set, a program change is only kept if some of its targets are.
+func (f Filter) Apply(change ProgramChange) (ProgramChange, bool) {
+	if (f.BountyOnly && !change.OffersBounties) || (f.PrivateOnly && !change.Private) {
+		return change, false
+	}
+	if f.Categories == nil {
+		return change, true
+	}
+
+	change.AddedTargets = f.filterTargets(change.AddedTargets)
+	change.RemovedTargets = f.filterTargets(change.RemovedTargets)
+
+	var changed []TargetChange
+	for _, target := range change.ChangedTargets {
+		if f.hasCategory(target.Category) {
+			changed = append(changed, target)
+		}
+	}
+	change.ChangedTargets = changed
+
+	return change, len(change.AddedTargets) > 0 || len(change.RemovedTargets) > 0 || len(change.ChangedTargets) > 0
+}
+
+// ApplyEvents returns the events selected by the filter, trimmed down with Apply
+func (f Filter) ApplyEvents(events []Event) []Event {
+	var filtered []Event
+	for _, event := range events {
+		if change, ok := f.Apply(event.ProgramChange); ok {
+			event.ProgramChange = change
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered
+}
+
+func (f Filter) filterTargets(targets []Target) []Target {
+	var filtered []Target
+	for _, target := range targets {
+		if f.hasCategory(target.Category) {
+			filtered = append(filtered, target)
+		}
+	}
+	return filtered
+}
+
+func (f Filter) hasCategory(category string) bool {
+	for _, c := range f.Categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package diff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteText prints changes in a human readable form: one block per program, with
+// + for additions, - for removals and ~ for changes
+func WriteText(w io.Writer, changes []ProgramChange) error {
+	var b strings.Builder
+
+	for _, change := range changes {
+		title := change.Platform + "/" + change.Handle
+		if change.Name != "" {
+			title += " (" + change.Name + ")"
+		}
+		if change.Url != "" {
+			title += " " + change.Url
+		}
+
+		switch change.Status {
+		case STATUS_ADDED:
+			fmt.Fprintf(&b, "+ %s: new program\n", title)
+		case STATUS_REMOVED:
+			fmt.Fprintf(&b, "- %s: program removed\n", title)
+		default:
+			fmt.Fprintf(&b, "~ %s\n", title)
+		}
+
+		for _, field := range change.Changes {
+			fmt.Fprintf(&b, "    ~ %s: %q -> %q\n", field.Field, field.Old, field.New)
+		}
+		for _, target := range change.AddedTargets {
+			fmt.Fprintf(&b, "    + %s\n", formatTarget(target.Target, target.Category, target.InScope))
+		}
+		for _, target := range change.RemovedTargets {
+			fmt.Fprintf(&b, "    - %s\n", formatTarget(target.Target, target.Category, target.InScope))
+		}
+		for _, target := range change.ChangedTargets {
+			for _, field := range target.Changes {
+				fmt.Fprintf(&b, "    ~ %s %s: %q -> %q\n", formatTarget(target.Target, "", target.InScope), field.Field, field.Old, field.New)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func formatTarget(target string, category string, inScope bool) string {
+	if category != "" {
+		target += " [" + category + "]"
+	}
+	if !inScope {
+		target = "(out of scope) " + target
+	}
+	return target
+}
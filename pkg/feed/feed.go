@@ -0,0 +1,190 @@
+package feed
+
+import (
+	"encoding/xml"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sw33tLie/bbscope/pkg/diff"
+)
+
+const (
+	FEED_TITLE = "bbscope scope changes"
+	FEED_LINK  = "https://github.com/sw33tLie/bbscope"
+	FEED_ID    = "urn:bbscope:changes"
+)
+
+var contentTemplate = template.Must(template.New("content").Parse(`
+{{- with .Changes}}<p>Program changes:</p><ul>{{range .}}<li>{{.Field}}: {{.Old}} &rarr; {{.New}}</li>{{end}}</ul>{{end}}
+{{- with .AddedTargets}}<p>Added targets:</p><ul>{{range .}}<li><code>{{.Target}}</code> ({{.Category}}{{if not .InScope}}, out of scope{{end}})</li>{{end}}</ul>{{end}}
+{{- with .RemovedTargets}}<p>Removed targets:</p><ul>{{range .}}<li><code>{{.Target}}</code> ({{.Category}}{{if not .InScope}}, out of scope{{end}})</li>{{end}}</ul>{{end}}
+{{- with .ChangedTargets}}<p>Changed targets:</p><ul>{{range $target := .}}{{range .Changes}}<li><code>{{$target.Target}}</code> {{.Field}}: {{.Old}} &rarr; {{.New}}</li>{{end}}{{end}}</ul>{{end}}
+{{- with .Url}}<p><a href="{{.}}">Program page</a></p>{{end}}`))
+
+// entryTitle describes the change in a line
+func entryTitle(event diff.Event) string {
+	name := event.Name
+	if name == "" {
+		name = event.Handle
+	}
+
+	switch event.Status {
+	case diff.STATUS_ADDED:
+		if event.Private {
+			return "New private program on " + event.Platform + ": " + name
+		}
+		return "New program on " + event.Platform + ": " + name
+	case diff.STATUS_REMOVED:
+		return "Program removed from " + event.Platform + ": " + name
+	default:
+		return "Scope changed on " + event.Platform + ": " + name
+	}
+}
+
+// entryID is a stable identifier of the change, so feed readers don't show it twice
+func entryID(event diff.Event) string {
+	return "urn:bbscope:" + event.ToRun + ":" + event.Platform + ":" + event.Handle
+}
+
+// entryContent renders the change as HTML
+func entryContent(event diff.Event) (string, error) {
+	var b strings.Builder
+	err := contentTemplate.Execute(&b, event)
+	return b.String(), err
+}
+
+type atomFeed struct {
+	XMLName   xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title     string      `xml:"title"`
+	ID        string      `xml:"id"`
+	Link      atomLink    `xml:"link"`
+	Updated   string      `xml:"updated"`
+	Author    atomAuthor  `xml:"author"`
+	Generator string      `xml:"generator"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Link    *atomLink   `xml:"link,omitempty"`
+	Updated string      `xml:"updated"`
+	Content atomContent `xml:"content"`
+}
+
+// WriteAtom writes events as an Atom feed, one entry per event, in the given order
+func WriteAtom(w io.Writer, events []diff.Event, updated time.Time) error {
+	feed := atomFeed{
+		Title:     FEED_TITLE,
+		ID:        FEED_ID,
+		Link:      atomLink{Href: FEED_LINK},
+		Updated:   updated.UTC().Format(time.RFC3339),
+		Author:    atomAuthor{Name: "bbscope"},
+		Generator: "bbscope",
+	}
+
+	for _, event := range events {
+		content, err := entryContent(event)
+		if err != nil {
+			return err
+		}
+		entry := atomEntry{
+			Title:   entryTitle(event),
+			ID:      entryID(event),
+			Updated: event.Time.UTC().Format(time.RFC3339),
+			Content: atomContent{Type: "html", Body: content},
+		}
+		if event.Url != "" {
+			entry.Link = &atomLink{Href: event.Url}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return writeXML(w, feed)
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Generator     string    `xml:"generator"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link,omitempty"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Description string  `xml:"description"`
+}
+
+// WriteRSS writes events as an RSS 2.0 feed, one item per event, in the given order
+func WriteRSS(w io.Writer, events []diff.Event, updated time.Time) error {
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         FEED_TITLE,
+			Link:          FEED_LINK,
+			Description:   "Programs and targets added, removed or changed on bug bounty platforms",
+			LastBuildDate: updated.UTC().Format(time.RFC1123Z),
+			Generator:     "bbscope",
+		},
+	}
+
+	for _, event := range events {
+		content, err := entryContent(event)
+		if err != nil {
+			return err
+		}
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       entryTitle(event),
+			Link:        event.Url,
+			GUID:        rssGUID{Value: entryID(event)},
+			PubDate:     event.Time.UTC().Format(time.RFC1123Z),
+			Description: content,
+		})
+	}
+
+	return writeXML(w, feed)
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
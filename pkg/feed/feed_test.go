@@ -0,0 +1,189 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sw33tLie/bbscope/pkg/diff"
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+var testTime = time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+func testEvents() []diff.Event {
+	return []diff.Event{
+		{
+			Time:    testTime,
+			FromRun: "run1",
+			ToRun:   "run2",
+			ProgramChange: diff.ProgramChange{
+				Platform: "h1",
+				Handle:   "program",
+				Name:     "Program",
+				Url:      "https://hackerone.com/program?type=team&x=<1>",
+				Status:   diff.STATUS_CHANGED,
+				Changes:  []diff.FieldChange{{Field: "max_bounty", Old: "100", New: "200"}},
+				AddedTargets: []diff.Target{
+					{ScopeElement: scope.ScopeElement{Target: "<script>.example.com", Category: scope.CategoryURL}, InScope: true},
+				},
+				RemovedTargets: []diff.Target{
+					{ScopeElement: scope.ScopeElement{Target: "old.example.com", Category: scope.CategoryURL}},
+				},
+			},
+		},
+		{
+			Time:          testTime.Add(-time.Hour),
+			FromRun:       "run0",
+			ToRun:         "run1",
+			ProgramChange: diff.ProgramChange{Platform: "it", Handle: "company/program", Status: diff.STATUS_ADDED, Private: true},
+		},
+	}
+}
+
+type parsedAtom struct {
+	XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string   `xml:"id"`
+	Updated string   `xml:"updated"`
+	Entries []struct {
+		Title   string `xml:"title"`
+		ID      string `xml:"id"`
+		Updated string `xml:"updated"`
+		Link    struct {
+			Href string `xml:"href,attr"`
+		} `xml:"link"`
+		Content struct {
+			Type string `xml:"type,attr"`
+			Body string `xml:",chardata"`
+		} `xml:"content"`
+	} `xml:"entry"`
+}
+
+type parsedRSS struct {
+	XMLName xml.Name `xml:"rss"`
+	Version string   `xml:"version,attr"`
+	Channel struct {
+		LastBuildDate string `xml:"lastBuildDate"`
+		Items         []struct {
+			Title string `xml:"title"`
+			Link  string `xml:"link"`
+			GUID  struct {
+				IsPermaLink string `xml:"isPermaLink,attr"`
+				Value       string `xml:",chardata"`
+			} `xml:"guid"`
+			PubDate     string `xml:"pubDate"`
+			Description string `xml:"description"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+func TestWriteAtom(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteAtom(&b, testEvents(), testTime); err != nil {
+		t.Fatal(err)
+	}
+
+	var feed parsedAtom
+	if err := xml.Unmarshal(b.Bytes(), &feed); err != nil {
+		t.Fatalf("invalid Atom feed: %v\n%s", err, b.String())
+	}
+	if feed.ID != FEED_ID || feed.Updated != "2024-05-06T07:08:09Z" || len(feed.Entries) != 2 {
+		t.Fatalf("unexpected feed %+v", feed)
+	}
+
+	entry := feed.Entries[0]
+	if entry.Title != "Scope changed on h1: Program" || entry.ID != "urn:bbscope:run2:h1:program" || entry.Updated != "2024-05-06T07:08:09Z" {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+	if entry.Link.Href != "https://hackerone.com/program?type=team&x=<1>" || entry.Content.Type != "html" {
+		t.Errorf("unexpected entry link or content type %+v", entry)
+	}
+	for _, want := range []string{
+		"<li>max_bounty: 100 &rarr; 200</li>",
+		"<code>&lt;script&gt;.example.com</code> (url)",
+		"<code>old.example.com</code> (url, out of scope)",
+		`<a href="https://hackerone.com/program?type=team&amp;x=%3c1%3e">`,
+	} {
+		if !strings.Contains(entry.Content.Body, want) {
+			t.Errorf("content does not contain %q: %s", want, entry.Content.Body)
+		}
+	}
+
+	if entry := feed.Entries[1]; entry.Title != "New private program on it: company/program" || entry.Link.Href != "" || entry.Content.Body != "" {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+}
+
+func TestWriteRSS(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteRSS(&b, testEvents(), testTime); err != nil {
+		t.Fatal(err)
+	}
+
+	var feed parsedRSS
+	if err := xml.Unmarshal(b.Bytes(), &feed); err != nil {
+		t.Fatalf("invalid RSS feed: %v\n%s", err, b.String())
+	}
+	if feed.Version != "2.0" || len(feed.Channel.Items) != 2 {
+		t.Fatalf("unexpected feed %+v", feed)
+	}
+	if _, err := time.Parse(time.RFC1123Z, feed.Channel.LastBuildDate); err != nil {
+		t.Errorf("invalid lastBuildDate: %v", err)
+	}
+
+	item := feed.Channel.Items[0]
+	if item.GUID.Value != "urn:bbscope:run2:h1:program" || item.GUID.IsPermaLink != "false" {
+		t.Errorf("unexpected guid %+v", item.GUID)
+	}
+	if pubDate, err := time.Parse(time.RFC1123Z, item.PubDate); err != nil || !pubDate.Equal(testTime) {
+		t.Errorf("pubDate %q = %v, %v, want %v", item.PubDate, pubDate, err, testTime)
+	}
+	if !strings.Contains(item.Description, "<code>&lt;script&gt;.example.com</code>") {
+		t.Errorf("unexpected description %s", item.Description)
+	}
+}
+
+func TestEntryIDStable(t *testing.T) {
+	events := testEvents()
+	later := events[0]
+	later.Time = later.Time.Add(24 * time.Hour)
+	later.AddedTargets = nil
+
+	// The same change found again by regenerating the feed keeps its ID, another run's gets a new one
+	if entryID(events[0]) != entryID(later) {
+		t.Error("the ID of a change depends on more than its run and program")
+	}
+	other := events[0]
+	other.ToRun = "run3"
+	if entryID(events[0]) == entryID(other) {
+		t.Error("changes found by different runs share an ID")
+	}
+	if entryID(events[0]) == entryID(events[1]) {
+		t.Error("changes to different programs share an ID")
+	}
+}
+
+func TestEmptyFeeds(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		write func(w io.Writer, events []diff.Event, updated time.Time) error
+		feed  interface{}
+	}{
+		{"atom", WriteAtom, &parsedAtom{}},
+		{"rss", WriteRSS, &parsedRSS{}},
+	} {
+		var b bytes.Buffer
+		if err := tt.write(&b, nil, testTime); err != nil {
+			t.Fatal(err)
+		}
+		if err := xml.Unmarshal(b.Bytes(), tt.feed); err != nil {
+			t.Errorf("%s: invalid empty feed: %v", tt.name, err)
+		}
+		if strings.Contains(b.String(), "<entry>") || strings.Contains(b.String(), "<item>") {
+			t.Errorf("%s: empty feed has entries", tt.name)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sw33tLie/bbscope/internal/utils"
+	"github.com/sw33tLie/bbscope/pkg/diff"
+	"github.com/sw33tLie/bbscope/pkg/output"
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+// Destination types
+const (
+	TYPE_SLACK   = "slack"
+	TYPE_DISCORD = "discord"
+	TYPE_JSON    = "json"
+)
+
+const (
+	DEFAULT_BATCH_SIZE  = 10
+	DEFAULT_RETRIES     = 3
+	DEFAULT_RETRY_DELAY = 2 * time.Second
+)
+
+// Longest message each destination type accepts. Longer messages are split on line boundaries.
+var maxMessageLength = map[string]int{
+	TYPE_SLACK:   4000,
+	TYPE_DISCORD: 2000,
+}
+
+var ErrInvalidDestination = errors.New("invalid webhook destination")
+
+// DEFAULT_TEMPLATE renders an event as a title line followed by a line per change
+const DEFAULT_TEMPLATE = `{{if eq .Status "added"}}New{{if .Private}} private{{end}} program{{else if eq .Status "removed"}}Program removed{{else}}Program changed{{end}}: {{or .Name .Handle}} ({{.Platform}}) {{.Url}}
+{{- range .Changes}}
+~ {{.Field}}: {{.Old}} -> {{.New}}{{end}}
+{{- range .AddedTargets}}
++ ` + "`{{.Target}}`" + ` [{{.Category}}]{{if not .InScope}} (out of scope){{end}}{{end}}
+{{- range .RemovedTargets}}
+- ` + "`{{.Target}}`" + ` [{{.Category}}]{{if not .InScope}} (out of scope){{end}}{{end}}
+{{- range $target := .ChangedTargets}}{{range .Changes}}
+~ ` + "`{{$target.Target}}`" + ` {{.Field}}: {{.Old}} -> {{.New}}{{end}}{{end}}`
+
+// Destination is a webhook, as configured under "webhooks" in the config file
+type Destination struct {
+	// Name identifies the destination in logs, it defaults to the type
+	Name string `mapstructure:"name"`
+	// Type is one of the TYPE_* constants
+	Type string `mapstructure:"type"`
+	URL  string `mapstructure:"url"`
+	// Template is executed with each diff.Event, DEFAULT_TEMPLATE is used when empty
+	Template string `mapstructure:"template"`
+	// BatchSize is the number of events sent in a single message
+	BatchSize   int  `mapstructure:"batch_size"`
+	BountyOnly  bool `mapstructure:"bounty_only"`
+	PrivateOnly bool `mapstructure:"private_only"`
+	// Categories is a comma separated list of the categories and groups accepted by scope.ParseCategories
+	Categories string `mapstructure:"categories"`
+}
+
+type destination struct {
+	Destination
+	template *template.Template
+	filter   diff.Filter
+}
+
+// Notifier sends events to webhooks. It is safe for concurrent use.
+type Notifier struct {
+	client       *http.Client
+	destinations []destination
+	// Retries is the number of times a failed request is sent again
+	Retries int
+	// RetryDelay is the wait before the first retry, doubled after each attempt,
+	// unless the server asks for a specific delay with Retry-After
+	RetryDelay time.Duration
+}
+
+// New checks the destinations and returns a Notifier sending to them with client
+func New(destinations []Destination, client *http.Client) (*Notifier, error) {
+	n := &Notifier{client: client, Retries: DEFAULT_RETRIES, RetryDelay: DEFAULT_RETRY_DELAY}
+
+	for _, d := range destinations {
+		if d.Type != TYPE_SLACK && d.Type != TYPE_DISCORD && d.Type != TYPE_JSON {
+			return nil, fmt.Errorf("%w: unknown type %q, valid choices are: %s, %s, %s", ErrInvalidDestination, d.Type, TYPE_SLACK, TYPE_DISCORD, TYPE_JSON)
+		}
+		if d.URL == "" {
+			return nil, fmt.Errorf("%w: missing url for %s", ErrInvalidDestination, d.Type)
+		}
+		if d.Name == "" {
+			d.Name = d.Type
+		}
+		if d.BatchSize <= 0 {
+			d.BatchSize = DEFAULT_BATCH_SIZE
+		}
+		if d.Template == "" {
+			d.Template = DEFAULT_TEMPLATE
+		}
+
+		tmpl, err := template.New(d.Name).Funcs(output.TemplateFuncs).Parse(d.Template)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s template: %v", ErrInvalidDestination, d.Name, err)
+		}
+
+		categories, err := scope.ParseCategories(d.Categories)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidDestination, d.Name, err)
+		}
+
+		n.destinations = append(n.destinations, destination{
+			Destination: d,
+			template:    tmpl,
+			filter:      diff.Filter{BountyOnly: d.BountyOnly, PrivateOnly: d.PrivateOnly, Categories: categories},
+		})
+	}
+	return n, nil
+}
+
+// Empty reports whether there is no destination to send to
+func (n *Notifier) Empty() bool {
+	return len(n.destinations) == 0
+}
+
+// Notify sends the events selected by each destination's filters, in batches.
+// Errors of every destination are joined in the returned error.
+func (n *Notifier) Notify(ctx context.Context, events []diff.Event) error {
+	var errs []error
+	for _, d := range n.destinations {
+		if err := n.notify(ctx, d, d.filter.ApplyEvents(events)); err != nil {
+			errs = append(errs, fmt.Errorf("%s webhook: %w", d.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) notify(ctx context.Context, d destination, events []diff.Event) error {
+	for start := 0; start < len(events); start += d.BatchSize {
+		end := start + d.BatchSize
+		if end > len(events) {
+			end = len(events)
+		}
+		batch := events[start:end]
+
+		var messages []string
+		for _, event := range batch {
+			var b strings.Builder
+			if err := d.template.Execute(&b, event); err != nil {
+				return err
+			}
+			messages = append(messages, strings.TrimRight(b.String(), "\n"))
+		}
+
+		for _, payload := range payloads(d.Type, batch, strings.Join(messages, "\n\n")) {
+			if err := n.post(ctx, d.URL, payload); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// payloads returns the request bodies carrying text, split to fit the destination's message length limit
+func payloads(destinationType string, events []diff.Event, text string) [][]byte {
+	var bodies [][]byte
+	switch destinationType {
+	case TYPE_SLACK:
+		for _, chunk := range splitText(text, maxMessageLength[TYPE_SLACK]) {
+			body, _ := json.Marshal(map[string]string{"text": chunk})
+			bodies = append(bodies, body)
+		}
+	case TYPE_DISCORD:
+		for _, chunk := range splitText(text, maxMessageLength[TYPE_DISCORD]) {
+			body, _ := json.Marshal(map[string]string{"content": chunk})
+			bodies = append(bodies, body)
+		}
+	default:
+		body, _ := json.Marshal(struct {
+			Text   string       `json:"text"`
+			Events []diff.Event `json:"events"`
+		}{text, events})
+		bodies = append(bodies, body)
+	}
+	return bodies
+}
+
+// splitText splits text into chunks of at most max bytes, cutting on line boundaries when possible
+func splitText(text string, max int) []string {
+	var chunks []string
+	for len(text) > max {
+		cut := strings.LastIndex(text[:max], "\n")
+		if cut <= 0 {
+			cut = max
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+		}
+		chunks = append(chunks, text[:cut])
+		text = strings.TrimPrefix(text[cut:], "\n")
+	}
+	return append(chunks, text)
+}
+
+// post sends body to url, retrying on network errors, 429 and 5xx responses
+func (n *Notifier) post(ctx context.Context, url string, body []byte) error {
+	delay := n.RetryDelay
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := n.postOnce(ctx, url, body)
+		if err == nil || retryAfter < 0 || attempt >= n.Retries || ctx.Err() != nil {
+			return err
+		}
+
+		wait := delay
+		if retryAfter > 0 {
+			wait = retryAfter
+		}
+		utils.Log.Warnf("%v, retrying in %s", err, wait)
+		if err := utils.Sleep(ctx, wait); err != nil {
+			return err
+		}
+		delay *= 2
+	}
+}
+
+// postOnce sends a single request. On failure it also returns how long to wait before retrying:
+// 0 for the default delay, or a negative duration when retrying is pointless.
+func (n *Notifier) postOnce(ctx context.Context, url string, body []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "bbscope")
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 1<<20))
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return 0, nil
+	}
+
+	err = fmt.Errorf("unexpected status code %d", res.StatusCode)
+	if res.StatusCode != http.StatusTooManyRequests && res.StatusCode < 500 {
+		return -1, err
+	}
+	if seconds, convErr := strconv.Atoi(res.Header.Get("Retry-After")); convErr == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second, err
+	}
+	return 0, err
+}
@@ -0,0 +1,238 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sw33tLie/bbscope/pkg/diff"
+)
+
+// receiver is a webhook server answering with the queued status codes, then 200
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	headers  []http.Header
+	bodies   []map[string]interface{}
+	times    []time.Time
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var body map[string]interface{}
+	data, _ := io.ReadAll(req.Body)
+	json.Unmarshal(data, &body)
+	r.bodies = append(r.bodies, body)
+	r.times = append(r.times, time.Now())
+
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status = r.statuses[0]
+		r.statuses = r.statuses[1:]
+	}
+	if len(r.headers) > 0 {
+		for key, values := range r.headers[0] {
+			w.Header()[key] = values
+		}
+		r.headers = r.headers[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func newTestNotifier(t *testing.T, url string, d Destination) *Notifier {
+	t.Helper()
+	d.URL = url
+	n, err := New([]Destination{d}, &http.Client{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n.RetryDelay = time.Millisecond
+	return n
+}
+
+func testEvents(count int) []diff.Event {
+	var events []diff.Event
+	for i := 0; i < count; i++ {
+		events = append(events, diff.Event{ProgramChange: diff.ProgramChange{
+			Platform:       "h1",
+			Handle:         "program" + strconv.Itoa(i),
+			Status:         diff.STATUS_ADDED,
+			OffersBounties: i%2 == 0,
+		}})
+	}
+	return events
+}
+
+func TestNotifyBatches(t *testing.T) {
+	tests := []struct {
+		name       string
+		d          Destination
+		events     int
+		wantPosts  int
+		wantEvents []int
+	}{
+		{"default batch size", Destination{Type: TYPE_JSON}, 25, 3, []int{10, 10, 5}},
+		{"batch size", Destination{Type: TYPE_JSON, BatchSize: 4}, 8, 2, []int{4, 4}},
+		{"filtered", Destination{Type: TYPE_JSON, BatchSize: 4, BountyOnly: true}, 8, 1, []int{4}},
+		{"nothing to send", Destination{Type: TYPE_JSON}, 0, 0, nil},
+		{"slack", Destination{Type: TYPE_SLACK, BatchSize: 2}, 3, 2, nil},
+		{"discord", Destination{Type: TYPE_DISCORD, BatchSize: 5}, 3, 1, nil},
+	}
+
+	for _, tt := range tests {
+		r := &receiver{}
+		server := httptest.NewServer(r)
+		n := newTestNotifier(t, server.URL, tt.d)
+
+		if err := n.Notify(context.Background(), testEvents(tt.events)); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		}
+		server.Close()
+
+		if len(r.bodies) != tt.wantPosts {
+			t.Errorf("%s: got %d requests, want %d", tt.name, len(r.bodies), tt.wantPosts)
+			continue
+		}
+		for i, body := range r.bodies {
+			switch tt.d.Type {
+			case TYPE_SLACK:
+				if text, _ := body["text"].(string); !strings.Contains(text, "New program") {
+					t.Errorf("%s: unexpected slack body %v", tt.name, body)
+				}
+			case TYPE_DISCORD:
+				if content, _ := body["content"].(string); strings.Count(content, "New program") != tt.events {
+					t.Errorf("%s: unexpected discord body %v", tt.name, body)
+				}
+			default:
+				if events, _ := body["events"].([]interface{}); len(events) != tt.wantEvents[i] {
+					t.Errorf("%s: request %d carried %d events, want %d", tt.name, i, len(events), tt.wantEvents[i])
+				}
+			}
+		}
+	}
+}
+
+func TestNotifyRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		headers   []http.Header
+		wantPosts int
+		wantErr   bool
+		// wantWait is the minimum time between the first two requests
+		wantWait time.Duration
+	}{
+		{name: "success", statuses: nil, wantPosts: 1},
+		{name: "server error", statuses: []int{500, 503}, wantPosts: 3},
+		{name: "too many requests", statuses: []int{429}, wantPosts: 2},
+		{
+			name:      "retry after",
+			statuses:  []int{429},
+			headers:   []http.Header{{"Retry-After": {"1"}}},
+			wantPosts: 2,
+			wantWait:  time.Second,
+		},
+		{name: "retries exhausted", statuses: []int{500, 500, 500, 500, 500}, wantPosts: DEFAULT_RETRIES + 1, wantErr: true},
+		{name: "bad request", statuses: []int{400}, wantPosts: 1, wantErr: true},
+		{name: "not found", statuses: []int{404}, wantPosts: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		r := &receiver{statuses: tt.statuses, headers: tt.headers}
+		server := httptest.NewServer(r)
+		n := newTestNotifier(t, server.URL, Destination{Type: TYPE_JSON})
+
+		err := n.Notify(context.Background(), testEvents(1))
+		server.Close()
+
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: got error %v", tt.name, err)
+		}
+		if len(r.times) != tt.wantPosts {
+			t.Errorf("%s: got %d requests, want %d", tt.name, len(r.times), tt.wantPosts)
+			continue
+		}
+		if tt.wantWait > 0 {
+			if wait := r.times[1].Sub(r.times[0]); wait < tt.wantWait {
+				t.Errorf("%s: retried after %s, want at least %s", tt.name, wait, tt.wantWait)
+			}
+		}
+	}
+}
+
+func TestNotifyCanceled(t *testing.T) {
+	r := &receiver{statuses: []int{500}, headers: []http.Header{{"Retry-After": {"60"}}}}
+	server := httptest.NewServer(r)
+	defer server.Close()
+	n := newTestNotifier(t, server.URL, Destination{Type: TYPE_JSON})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := n.Notify(ctx, testEvents(1)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want the context error", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("Notify kept waiting after the context was done")
+	}
+}
+
+func TestNewInvalidDestination(t *testing.T) {
+	for _, d := range []Destination{
+		{Type: "teams", URL: "https://example.com"},
+		{Type: TYPE_SLACK},
+		{Type: TYPE_SLACK, URL: "https://example.com", Template: "{{.Handle"},
+		{Type: TYPE_SLACK, URL: "https://example.com", Categories: "nope"},
+	} {
+		if _, err := New([]Destination{d}, http.DefaultClient); !errors.Is(err, ErrInvalidDestination) {
+			t.Errorf("New(%+v) error = %v, want ErrInvalidDestination", d, err)
+		}
+	}
+}
+
+func TestSplitText(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		max        int
+		wantChunks int
+	}{
+		{"short", "hello", 10, 1},
+		{"exact", strings.Repeat("a", 10), 10, 1},
+		{"lines", "aaaa\nbbbb\ncccc", 10, 2},
+		{"long line", strings.Repeat("a", 25), 10, 3},
+		{"slack multibyte lines", strings.Repeat("ünïcödé 🐞 target\n", 500), maxMessageLength[TYPE_SLACK], 0},
+		{"slack multibyte line", strings.Repeat("é", 3000) + strings.Repeat("🐞", 1000), maxMessageLength[TYPE_SLACK], 0},
+		{"discord multibyte line", strings.Repeat("日本語", 1000), maxMessageLength[TYPE_DISCORD], 0},
+		{"discord rune at limit", "a" + strings.Repeat("🐞", 1000), maxMessageLength[TYPE_DISCORD], 0},
+	}
+
+	for _, tt := range tests {
+		chunks := splitText(tt.text, tt.max)
+		if tt.wantChunks > 0 && len(chunks) != tt.wantChunks {
+			t.Errorf("%s: got %d chunks, want %d", tt.name, len(chunks), tt.wantChunks)
+		}
+		for i, chunk := range chunks {
+			if len(chunk) > tt.max {
+				t.Errorf("%s: chunk %d is %d bytes long, max is %d", tt.name, i, len(chunk), tt.max)
+			}
+			if !utf8.ValidString(chunk) {
+				t.Errorf("%s: chunk %d is not valid UTF-8", tt.name, i)
+			}
+		}
+		if joined := strings.Join(chunks, ""); strings.ReplaceAll(joined, "\n", "") != strings.ReplaceAll(tt.text, "\n", "") {
+			t.Errorf("%s: chunks do not add up to the text", tt.name)
+		}
+	}
+}
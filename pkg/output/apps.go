@@ -0,0 +1,165 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+var (
+	// Android package names and iOS bundle IDs are both reverse domain names
+	appIDRegex      = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*(?:\.[A-Za-z0-9_-]+)+$`)
+	appStoreIDRegex = regexp.MustCompile(`(?:^|/|\b)id(\d{5,})\b|^(\d{5,})$`)
+)
+
+// Host names of the app stores, which look like package names but never are one
+const (
+	HOST_PLAY_STORE = "play.google.com"
+	HOST_APP_STORE  = "apps.apple.com"
+	HOST_ITUNES     = "itunes.apple.com"
+	HOST_TESTFLIGHT = "testflight.apple.com"
+)
+
+// storeHosts are recognized in store URLs listed without their scheme
+var storeHosts = map[string]bool{
+	HOST_PLAY_STORE: true,
+	HOST_APP_STORE:  true,
+	HOST_ITUNES:     true,
+	HOST_TESTFLIGHT: true,
+}
+
+// app is a mobile application, as found across every program listing it
+type app struct {
+	// Platform is scope.CategoryAndroid or scope.CategoryIOS
+	Platform string `json:"platform"`
+	// ID is the Android package name or the iOS bundle ID, when known
+	ID string `json:"id,omitempty"`
+	// AppStoreID is the numeric App Store ID of iOS apps, when known
+	AppStoreID string `json:"app_store_id,omitempty"`
+	// StoreURL is the store page, or the invite link of TestFlight betas, which have no other ID
+	StoreURL string   `json:"store_url,omitempty"`
+	Programs []string `json:"programs"`
+}
+
+func (a app) key() string {
+	return a.Platform + "|" + a.ID + "|" + a.AppStoreID + "|" + a.StoreURL
+}
+
+// appsWriter collects the mobile apps of every program and prints them on Close, as
+// a JSON array or CSV
+type appsWriter struct {
+	w    io.Writer
+	opts Options
+	csv  bool
+	apps []*app
+	seen map[string]*app
+}
+
+func newAppsWriter(w io.Writer, opts Options) (Writer, error) {
+	return &appsWriter{w: w, opts: opts, seen: make(map[string]*app)}, nil
+}
+
+func newAppsCSVWriter(w io.Writer, opts Options) (Writer, error) {
+	return &appsWriter{w: w, opts: opts, csv: true, seen: make(map[string]*app)}, nil
+}
+
+func (a *appsWriter) WriteProgram(pData scope.ProgramData) error {
+	program := pData.Platform + ":" + pData.Handle
+	for _, element := range elements(pData, a.opts) {
+		parsed, ok := parseApp(element)
+		if !ok {
+			continue
+		}
+
+		existing, ok := a.seen[parsed.key()]
+		if !ok {
+			existing = &parsed
+			a.seen[parsed.key()] = existing
+			a.apps = append(a.apps, existing)
+		}
+		if len(existing.Programs) == 0 || existing.Programs[len(existing.Programs)-1] != program {
+			existing.Programs = append(existing.Programs, program)
+		}
+	}
+	return nil
+}
+
+func (a *appsWriter) Close() error {
+	if a.csv {
+		w := csv.NewWriter(a.w)
+		w.Write([]string{"platform", "id", "app_store_id", "store_url", "programs"})
+		for _, app := range a.apps {
+			w.Write([]string{app.Platform, app.ID, app.AppStoreID, app.StoreURL, strings.Join(app.Programs, " ")})
+		}
+		w.Flush()
+		return w.Error()
+	}
+
+	apps := a.apps
+	if apps == nil {
+		apps = []*app{}
+	}
+	encoder := json.NewEncoder(a.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(apps)
+}
+
+// parseApp reads a mobile app out of a scope element. Store URLs are recognized whatever the
+// element category, while package names, bundle IDs and App Store IDs need an android or ios
+// category to tell which store they belong to.
+func parseApp(element scope.ScopeElement) (app, bool) {
+	target := strings.TrimSpace(element.Target)
+	platform := element.Category
+
+	// Store URLs are sometimes listed without their scheme
+	host, _, _ := strings.Cut(target, "/")
+	if storeHosts[strings.TrimPrefix(strings.ToLower(host), "www.")] {
+		target = "https://" + target
+	}
+
+	if u, err := url.Parse(target); err == nil && u.Host != "" {
+		switch strings.TrimPrefix(strings.ToLower(u.Host), "www.") {
+		case HOST_PLAY_STORE:
+			platform = scope.CategoryAndroid
+			target = u.Query().Get("id")
+		case HOST_APP_STORE, HOST_ITUNES:
+			platform = scope.CategoryIOS
+			target = u.Path
+		case HOST_TESTFLIGHT:
+			code := strings.TrimPrefix(u.Path, "/join/")
+			if code == u.Path || code == "" || strings.Contains(code, "/") {
+				return app{}, false
+			}
+			return app{Platform: scope.CategoryIOS, StoreURL: "https://" + HOST_TESTFLIGHT + "/join/" + code}, true
+		default:
+			return app{}, false
+		}
+	}
+
+	switch platform {
+	case scope.CategoryAndroid:
+		if !appIDRegex.MatchString(target) {
+			return app{}, false
+		}
+		return app{
+			Platform: platform,
+			ID:       target,
+			StoreURL: "https://play.google.com/store/apps/details?id=" + url.QueryEscape(target),
+		}, true
+
+	case scope.CategoryIOS:
+		if m := appStoreIDRegex.FindStringSubmatch(target); m != nil {
+			id := m[1] + m[2]
+			return app{Platform: platform, AppStoreID: id, StoreURL: "https://apps.apple.com/app/id" + id}, true
+		}
+		if appIDRegex.MatchString(target) {
+			return app{Platform: platform, ID: target}, true
+		}
+	}
+	return app{}, false
+}
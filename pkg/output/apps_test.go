@@ -0,0 +1,55 @@
+package output
+
+import (
+	"testing"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+func TestParseApp(t *testing.T) {
+	tests := []struct {
+		target   string
+		category string
+		want     app
+		wantOK   bool
+	}{
+		// Play Store
+		{"https://play.google.com/store/apps/details?id=com.example.app&hl=en", scope.CategoryOther, app{Platform: scope.CategoryAndroid, ID: "com.example.app", StoreURL: "https://play.google.com/store/apps/details?id=com.example.app"}, true},
+		{"play.google.com/store/apps/details?id=com.example.app", scope.CategoryURL, app{Platform: scope.CategoryAndroid, ID: "com.example.app", StoreURL: "https://play.google.com/store/apps/details?id=com.example.app"}, true},
+		{"https://play.google.com/store/apps/developer?id=Example", scope.CategoryAndroid, app{}, false},
+
+		// App Store
+		{"https://apps.apple.com/us/app/example/id123456789", scope.CategoryOther, app{Platform: scope.CategoryIOS, AppStoreID: "123456789", StoreURL: "https://apps.apple.com/app/id123456789"}, true},
+		{"itunes.apple.com/app/id123456789?mt=8", scope.CategoryURL, app{Platform: scope.CategoryIOS, AppStoreID: "123456789", StoreURL: "https://apps.apple.com/app/id123456789"}, true},
+		{"123456789", scope.CategoryIOS, app{Platform: scope.CategoryIOS, AppStoreID: "123456789", StoreURL: "https://apps.apple.com/app/id123456789"}, true},
+		{"https://apps.apple.com/us/developer/example", scope.CategoryIOS, app{}, false},
+
+		// TestFlight
+		{"https://testflight.apple.com/join/AbC123", scope.CategoryOther, app{Platform: scope.CategoryIOS, StoreURL: "https://testflight.apple.com/join/AbC123"}, true},
+		{"testflight.apple.com/join/AbC123", scope.CategoryIOS, app{Platform: scope.CategoryIOS, StoreURL: "https://testflight.apple.com/join/AbC123"}, true},
+		{"https://testflight.apple.com/", scope.CategoryIOS, app{}, false},
+
+		// Bare package names and bundle IDs
+		{"com.example.app", scope.CategoryAndroid, app{Platform: scope.CategoryAndroid, ID: "com.example.app", StoreURL: "https://play.google.com/store/apps/details?id=com.example.app"}, true},
+		{" com.example.ios ", scope.CategoryIOS, app{Platform: scope.CategoryIOS, ID: "com.example.ios"}, true},
+		{"com.example.app", scope.CategoryOther, app{}, false},
+		{"com.example.app", scope.CategoryURL, app{}, false},
+
+		// Store hosts are not app IDs
+		{"play.google.com", scope.CategoryAndroid, app{}, false},
+		{"apps.apple.com", scope.CategoryIOS, app{}, false},
+		{"itunes.apple.com", scope.CategoryIOS, app{}, false},
+		{"testflight.apple.com", scope.CategoryIOS, app{}, false},
+		{"www.play.google.com", scope.CategoryAndroid, app{}, false},
+
+		// Other links
+		{"https://example.com/app.apk", scope.CategoryAndroid, app{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseApp(scope.ScopeElement{Target: tt.target, Category: tt.category})
+		if ok != tt.wantOK || got.key() != tt.want.key() {
+			t.Errorf("parseApp(%q, %s) = %+v, %v, want %+v, %v", tt.target, tt.category, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
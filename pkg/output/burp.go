@@ -0,0 +1,134 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+// burpRule is an advanced mode entry of Burp's target scope
+type burpRule struct {
+	Enabled  bool   `json:"enabled"`
+	Protocol string `json:"protocol"`
+	Host     string `json:"host"`
+	Port     string `json:"port,omitempty"`
+	File     string `json:"file,omitempty"`
+}
+
+type burpScope struct {
+	AdvancedMode bool       `json:"advanced_mode"`
+	Include      []burpRule `json:"include"`
+	Exclude      []burpRule `json:"exclude"`
+}
+
+// burpProjectOptions is the subset of Burp's project options file holding the target scope
+type burpProjectOptions struct {
+	Target struct {
+		Scope burpScope `json:"scope"`
+	} `json:"target"`
+}
+
+// burpWriter builds Burp project options with the in-scope web targets as include rules and
+// the out-of-scope ones as exclude rules. Rules from every program are merged into a single
+// file printed on Close, unless Options.Dir is set, in which case a file is written per program.
+// Include and exclude rules are deduplicated separately, so a host one program has in scope and
+// another excludes gets both rules whatever the order programs come in. Burp gives exclude rules
+// precedence, so such a host ends up out of scope.
+type burpWriter struct {
+	w           io.Writer
+	opts        Options
+	merged      burpScope
+	seenInclude map[burpRule]bool
+	seenExclude map[burpRule]bool
+}
+
+func newBurpWriter(w io.Writer, opts Options) (Writer, error) {
+	return &burpWriter{
+		w:           w,
+		opts:        opts,
+		merged:      newBurpScope(),
+		seenInclude: make(map[burpRule]bool),
+		seenExclude: make(map[burpRule]bool),
+	}, nil
+}
+
+func newBurpScope() burpScope {
+	return burpScope{AdvancedMode: true, Include: []burpRule{}, Exclude: []burpRule{}}
+}
+
+func (b *burpWriter) WriteProgram(pData scope.ProgramData) error {
+	if b.opts.Dir == "" {
+		b.merged.Include = b.appendRules(b.merged.Include, pData.InScope, true, b.seenInclude)
+		b.merged.Exclude = b.appendRules(b.merged.Exclude, pData.OutOfScope, false, b.seenExclude)
+		return nil
+	}
+
+	s := newBurpScope()
+	s.Include = b.appendRules(s.Include, pData.InScope, true, make(map[burpRule]bool))
+	if len(s.Include) == 0 {
+		return nil
+	}
+	s.Exclude = b.appendRules(s.Exclude, pData.OutOfScope, false, make(map[burpRule]bool))
+
+	f, err := os.Create(filepath.Join(b.opts.Dir, programFileName(pData, ".burp.json")))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return writeBurpScope(f, s)
+}
+
+// appendRules converts the web targets among elements into rules, skipping those already in seen
+func (b *burpWriter) appendRules(rules []burpRule, elements []scope.ScopeElement, inScope bool, seen map[burpRule]bool) []burpRule {
+	for _, t := range webTargets(elements, inScope) {
+		rule := burpRule{
+			Enabled:  true,
+			Protocol: "any",
+			Host:     "^" + wildcardRegex(t.Host) + "$",
+		}
+		if t.Scheme != "" {
+			rule.Protocol = t.Scheme
+		}
+		if t.Port != "" {
+			rule.Port = "^" + t.Port + "$"
+		}
+		if t.Path != "" {
+			rule.File = "^" + wildcardRegex(strings.TrimSuffix(t.Path, "*")) + ".*"
+		}
+
+		if !seen[rule] {
+			seen[rule] = true
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+func (b *burpWriter) Close() error {
+	if b.opts.Dir != "" {
+		return nil
+	}
+	return writeBurpScope(b.w, b.merged)
+}
+
+func writeBurpScope(w io.Writer, s burpScope) error {
+	var options burpProjectOptions
+	options.Target.Scope = s
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(options)
+}
+
+var fileNameRegex = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// programFileName returns a file name for per program output, unique across platforms
+func programFileName(pData scope.ProgramData, extension string) string {
+	return pData.Platform + "_" + fileNameRegex.ReplaceAllString(pData.Handle, "_") + extension
+}
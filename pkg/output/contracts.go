@@ -0,0 +1,75 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+// chainContracts lists the contracts deployed on a chain
+type chainContracts struct {
+	ChainID   int64           `json:"chain_id"`
+	Chain     string          `json:"chain"`
+	Contracts []*contractInfo `json:"contracts"`
+}
+
+type contractInfo struct {
+	Address     string   `json:"address"`
+	ExplorerURL string   `json:"explorer_url"`
+	Programs    []string `json:"programs"`
+}
+
+// contractsWriter prints the smart contracts of every program as a JSON array with an
+// entry per chain, sorted by chain ID, so that contracts can be loaded a chain at a time
+type contractsWriter struct {
+	w      io.Writer
+	opts   Options
+	chains map[int64]*chainContracts
+	seen   map[string]*contractInfo
+}
+
+func newContractsWriter(w io.Writer, opts Options) (Writer, error) {
+	return &contractsWriter{w: w, opts: opts, chains: make(map[int64]*chainContracts), seen: make(map[string]*contractInfo)}, nil
+}
+
+func (c *contractsWriter) WriteProgram(pData scope.ProgramData) error {
+	program := pData.Platform + ":" + pData.Handle
+	for _, element := range elements(pData, c.opts) {
+		contract := element.Contract
+		if contract == nil {
+			continue
+		}
+
+		chain, ok := c.chains[contract.ChainID]
+		if !ok {
+			chain = &chainContracts{ChainID: contract.ChainID, Chain: contract.Chain}
+			c.chains[contract.ChainID] = chain
+		}
+
+		key := contract.Chain + "|" + contract.Address
+		info, ok := c.seen[key]
+		if !ok {
+			info = &contractInfo{Address: contract.Address, ExplorerURL: contract.ExplorerURL}
+			c.seen[key] = info
+			chain.Contracts = append(chain.Contracts, info)
+		}
+		if len(info.Programs) == 0 || info.Programs[len(info.Programs)-1] != program {
+			info.Programs = append(info.Programs, program)
+		}
+	}
+	return nil
+}
+
+func (c *contractsWriter) Close() error {
+	chains := make([]*chainContracts, 0, len(c.chains))
+	for _, chain := range c.chains {
+		chains = append(chains, chain)
+	}
+	sort.Slice(chains, func(i, j int) bool { return chains[i].ChainID < chains[j].ChainID })
+
+	encoder := json.NewEncoder(c.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(chains)
+}
@@ -0,0 +1,62 @@
+package output
+
+import (
+	"encoding/csv"
+	"io"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+// csvWriter prints the fields selected by the output flags as quoted CSV, with a header row
+type csvWriter struct {
+	w      *csv.Writer
+	fields []scope.Field
+	opts   Options
+}
+
+func newCSVWriter(w io.Writer, opts Options) (Writer, error) {
+	return newDelimitedWriter(w, opts, ',')
+}
+
+func newTSVWriter(w io.Writer, opts Options) (Writer, error) {
+	return newDelimitedWriter(w, opts, '\t')
+}
+
+func newDelimitedWriter(w io.Writer, opts Options, comma rune) (Writer, error) {
+	fields, err := scope.ParseFields(opts.OutputFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &csvWriter{w: csv.NewWriter(w), fields: fields, opts: opts}
+	c.w.Comma = comma
+
+	header := make([]string, 0, len(fields))
+	for _, field := range fields {
+		header = append(header, field.Name)
+	}
+	if err := c.w.Write(header); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *csvWriter) WriteProgram(pData scope.ProgramData) error {
+	for _, element := range elements(pData, c.opts) {
+		record := make([]string, 0, len(c.fields))
+		for _, field := range c.fields {
+			record = append(record, field.Value(pData, element))
+		}
+		if err := c.w.Write(record); err != nil {
+			return err
+		}
+	}
+	// Flush after every program so that results keep streaming
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,249 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+// Files written by the ipranges format when Options.Dir is set
+const (
+	IPRANGES_INCLUDE_FILE = "ipranges-include.txt"
+	IPRANGES_EXCLUDE_FILE = "ipranges-exclude.txt"
+)
+
+// ipRange is an inclusive range of addresses of the same family
+type ipRange struct {
+	from netip.Addr
+	to   netip.Addr
+}
+
+// ipRangesWriter collects the IP addresses and ranges of every program, merges them and prints
+// them as CIDRs on Close. The out-of-scope ranges of every program are cut out of the in-scope
+// ones, so the include list never touches an excluded address.
+type ipRangesWriter struct {
+	w       io.Writer
+	opts    Options
+	include []ipRange
+	exclude []ipRange
+}
+
+func newIPRangesWriter(w io.Writer, opts Options) (Writer, error) {
+	return &ipRangesWriter{w: w, opts: opts}, nil
+}
+
+func (i *ipRangesWriter) WriteProgram(pData scope.ProgramData) error {
+	for _, element := range pData.InScope {
+		// In-scope targets must be addresses or ranges as a whole, while any
+		// address found in an out-of-scope target is excluded
+		ranges, ok := parseIPRanges(element.Target)
+		if ok {
+			i.include = append(i.include, ranges...)
+		}
+	}
+	for _, element := range pData.OutOfScope {
+		ranges, _ := parseIPRanges(element.Target)
+		i.exclude = append(i.exclude, ranges...)
+	}
+	return nil
+}
+
+func (i *ipRangesWriter) Close() error {
+	// A program may include a range another one excludes part of, the exclusion wins
+	exclude := mergeIPRanges(i.exclude)
+	include := subtractIPRanges(mergeIPRanges(i.include), exclude)
+
+	if i.opts.Dir == "" {
+		if i.opts.OutOfScope {
+			return writeIPRanges(i.w, exclude)
+		}
+		return writeIPRanges(i.w, include)
+	}
+
+	for _, file := range []struct {
+		name   string
+		ranges []ipRange
+	}{
+		{IPRANGES_INCLUDE_FILE, include},
+		{IPRANGES_EXCLUDE_FILE, exclude},
+	} {
+		f, err := os.Create(filepath.Join(i.opts.Dir, file.name))
+		if err != nil {
+			return err
+		}
+		err = writeIPRanges(f, file.ranges)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeIPRanges prints ranges as CIDRs, one per line
+func writeIPRanges(w io.Writer, ranges []ipRange) error {
+	for _, r := range ranges {
+		for _, prefix := range r.prefixes() {
+			if _, err := fmt.Fprintln(w, prefix); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseIPRanges parses the addresses and ranges in target, which may hold several of them
+// separated by commas or spaces. Supported notations are single addresses, CIDRs,
+// "a.b.c.d-e" and "a.b.c.d-a.b.c.e". It reports false if some part of target is not an
+// address or range, along with the ranges it could parse.
+func parseIPRanges(target string) ([]ipRange, bool) {
+	var ranges []ipRange
+	ok := true
+
+	tokens := strings.FieldsFunc(target, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\n'
+	})
+	for _, token := range tokens {
+		r, err := parseIPRange(token)
+		if err != nil {
+			ok = false
+			continue
+		}
+		ranges = append(ranges, r)
+	}
+
+	return ranges, ok && len(ranges) > 0
+}
+
+func parseIPRange(s string) (ipRange, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return ipRange{}, err
+		}
+		prefix = prefix.Masked()
+		return ipRange{from: prefix.Addr(), to: lastAddr(prefix)}, nil
+	}
+
+	from, to, isRange := strings.Cut(s, "-")
+	fromAddr, err := netip.ParseAddr(from)
+	if err != nil {
+		return ipRange{}, err
+	}
+	fromAddr = fromAddr.Unmap()
+	if !isRange {
+		return ipRange{from: fromAddr, to: fromAddr}, nil
+	}
+
+	toAddr, err := netip.ParseAddr(to)
+	if err != nil {
+		// a.b.c.d-e notation, where e replaces the last octet
+		lastOctet, convErr := strconv.ParseUint(to, 10, 8)
+		if convErr != nil || !fromAddr.Is4() {
+			return ipRange{}, err
+		}
+		octets := fromAddr.As4()
+		octets[3] = byte(lastOctet)
+		toAddr = netip.AddrFrom4(octets)
+	}
+	toAddr = toAddr.Unmap()
+
+	if fromAddr.BitLen() != toAddr.BitLen() || toAddr.Less(fromAddr) {
+		return ipRange{}, fmt.Errorf("invalid IP range %q", s)
+	}
+	return ipRange{from: fromAddr, to: toAddr}, nil
+}
+
+// mergeIPRanges sorts ranges and merges the overlapping and adjacent ones
+func mergeIPRanges(ranges []ipRange) []ipRange {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	sorted := make([]ipRange, len(ranges))
+	copy(sorted, ranges)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].from.Less(sorted[j].from) })
+
+	merged := []ipRange{sorted[0]}
+	for _, r := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		next := last.to.Next()
+		if r.from.BitLen() == last.to.BitLen() && (!next.IsValid() || !next.Less(r.from)) {
+			if last.to.Less(r.to) {
+				last.to = r.to
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// subtractIPRanges removes the addresses in exclude from include. Both must be merged.
+func subtractIPRanges(include []ipRange, exclude []ipRange) []ipRange {
+	var result []ipRange
+	for _, r := range include {
+		for _, e := range exclude {
+			if e.from.BitLen() != r.from.BitLen() || e.to.Less(r.from) || r.to.Less(e.from) {
+				continue
+			}
+			if r.from.Less(e.from) {
+				result = append(result, ipRange{from: r.from, to: e.from.Prev()})
+			}
+			if !e.to.Less(r.to) {
+				r.from = netip.Addr{}
+				break
+			}
+			r.from = e.to.Next()
+		}
+		if r.from.IsValid() {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// prefixes returns the smallest list of CIDRs covering exactly the range
+func (r ipRange) prefixes() []netip.Prefix {
+	var prefixes []netip.Prefix
+	from := r.from
+	for {
+		// Largest block starting at from that doesn't go past the end of the range
+		bits := from.BitLen()
+		for bits > 0 {
+			prefix := netip.PrefixFrom(from, bits-1)
+			if prefix.Masked().Addr() != from || r.to.Less(lastAddr(prefix)) {
+				break
+			}
+			bits--
+		}
+		prefix := netip.PrefixFrom(from, bits)
+		prefixes = append(prefixes, prefix)
+
+		last := lastAddr(prefix)
+		if !last.Less(r.to) {
+			return prefixes
+		}
+		from = last.Next()
+	}
+}
+
+// lastAddr returns the last address of prefix
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	addr := prefix.Masked().Addr()
+	bytes := addr.AsSlice()
+	for bit := prefix.Bits(); bit < addr.BitLen(); bit++ {
+		bytes[bit/8] |= 1 << (7 - bit%8)
+	}
+	last, _ := netip.AddrFromSlice(bytes)
+	return last
+}
@@ -0,0 +1,220 @@
+package output
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+// mustParseIPRanges parses comma separated ranges, failing the test on invalid ones
+func mustParseIPRanges(t *testing.T, s string) []ipRange {
+	t.Helper()
+	if s == "" {
+		return nil
+	}
+	ranges, ok := parseIPRanges(s)
+	if !ok {
+		t.Fatalf("invalid ranges %q", s)
+	}
+	return ranges
+}
+
+// cidrs returns the CIDRs covering ranges, comma separated
+func cidrs(ranges []ipRange) string {
+	var out []string
+	for _, r := range ranges {
+		for _, prefix := range r.prefixes() {
+			out = append(out, prefix.String())
+		}
+	}
+	return strings.Join(out, ",")
+}
+
+func TestParseIPRanges(t *testing.T) {
+	tests := []struct {
+		target string
+		want   string
+		wantOK bool
+	}{
+		{"10.0.0.1", "10.0.0.1/32", true},
+		{"10.0.0.0/24", "10.0.0.0/24", true},
+		{"10.0.0.7/24", "10.0.0.0/24", true},
+		{"10.0.0.0-255", "10.0.0.0/24", true},
+		{"10.0.0.0-10.0.1.255", "10.0.0.0/23", true},
+		{"10.0.0.1, 10.0.0.2", "10.0.0.1/32,10.0.0.2/32", true},
+		{"2001:db8::/32", "2001:db8::/32", true},
+		{"::ffff:10.0.0.1", "10.0.0.1/32", true},
+		{"0.0.0.0/0", "0.0.0.0/0", true},
+		{"10.0.0.5-10.0.0.1", "", false},
+		{"10.0.0.1-2001:db8::1", "", false},
+		{"example.com", "", false},
+		{"10.0.0.1 example.com", "10.0.0.1/32", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		ranges, ok := parseIPRanges(tt.target)
+		if ok != tt.wantOK || cidrs(ranges) != tt.want {
+			t.Errorf("parseIPRanges(%q) = %q, %v, want %q, %v", tt.target, cidrs(ranges), ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestMergeIPRanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		ranges string
+		want   string
+	}{
+		{"empty", "", ""},
+		{"adjacent", "10.0.0.0/25,10.0.0.128/25", "10.0.0.0/24"},
+		{"overlapping", "10.0.0.0/24,10.0.0.128-10.0.1.255", "10.0.0.0/23"},
+		{"contained", "10.0.0.0/16,10.0.5.0/24", "10.0.0.0/16"},
+		{"unsorted", "10.0.2.0/24,10.0.0.0/24,10.0.1.0/24", "10.0.0.0/23,10.0.2.0/24"},
+		{"gap", "10.0.0.0/24,10.0.2.0/24", "10.0.0.0/24,10.0.2.0/24"},
+		{"duplicates", "10.0.0.1,10.0.0.1", "10.0.0.1/32"},
+		{"ipv6 adjacent", "2001:db8::/33,2001:db8:8000::/33", "2001:db8::/32"},
+		{"families kept apart", "0.0.0.0/0,::/0", "0.0.0.0/0,::/0"},
+		{"end of address space", "255.255.255.0/24,255.255.255.255", "255.255.255.0/24"},
+	}
+
+	for _, tt := range tests {
+		got := cidrs(mergeIPRanges(mustParseIPRanges(t, tt.ranges)))
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSubtractIPRanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		include string
+		exclude string
+		want    string
+	}{
+		{"no exclude", "10.0.0.0/24", "", "10.0.0.0/24"},
+		{"disjoint", "10.0.0.0/24", "10.0.1.0/24", "10.0.0.0/24"},
+		{"split in two", "10.0.0.0/24", "10.0.0.128", "10.0.0.0/25,10.0.0.129/32,10.0.0.130/31,10.0.0.132/30,10.0.0.136/29,10.0.0.144/28,10.0.0.160/27,10.0.0.192/26"},
+		{"head", "10.0.0.0/24", "10.0.0.0/25", "10.0.0.128/25"},
+		{"tail", "10.0.0.0/24", "10.0.0.128/25", "10.0.0.0/25"},
+		{"whole", "10.0.0.0/24", "10.0.0.0/16", ""},
+		{"several", "10.0.0.0/24", "10.0.0.0/26,10.0.0.128/26", "10.0.0.64/26,10.0.0.192/26"},
+		{"across includes", "10.0.0.0/24,10.0.2.0/24", "10.0.0.128-10.0.2.127", "10.0.0.0/25,10.0.2.128/25"},
+		{"other family", "10.0.0.0/24", "::/0", "10.0.0.0/24"},
+		{"ipv6", "2001:db8::/32", "2001:db8::/33", "2001:db8:8000::/33"},
+		{"whole space", "0.0.0.0/0", "0.0.0.0/1", "128.0.0.0/1"},
+		{"last address", "0.0.0.0/0", "255.255.255.255", "0.0.0.0/1,128.0.0.0/2,192.0.0.0/3,224.0.0.0/4,240.0.0.0/5,248.0.0.0/6,252.0.0.0/7,254.0.0.0/8,255.0.0.0/9,255.128.0.0/10,255.192.0.0/11,255.224.0.0/12,255.240.0.0/13,255.248.0.0/14,255.252.0.0/15,255.254.0.0/16,255.255.0.0/17,255.255.128.0/18,255.255.192.0/19,255.255.224.0/20,255.255.240.0/21,255.255.248.0/22,255.255.252.0/23,255.255.254.0/24,255.255.255.0/25,255.255.255.128/26,255.255.255.192/27,255.255.255.224/28,255.255.255.240/29,255.255.255.248/30,255.255.255.252/31,255.255.255.254/32"},
+		{"first address", "10.0.0.0-3", "10.0.0.0", "10.0.0.1/32,10.0.0.2/31"},
+	}
+
+	for _, tt := range tests {
+		include := mergeIPRanges(mustParseIPRanges(t, tt.include))
+		exclude := mergeIPRanges(mustParseIPRanges(t, tt.exclude))
+		got := cidrs(subtractIPRanges(include, exclude))
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIPRangePrefixes(t *testing.T) {
+	tests := []struct {
+		name string
+		rng  string
+		want []string
+	}{
+		{"single ipv4", "10.0.0.1", []string{"10.0.0.1/32"}},
+		{"single ipv6", "2001:db8::1", []string{"2001:db8::1/128"}},
+		{"aligned", "10.0.0.0-255", []string{"10.0.0.0/24"}},
+		{"unaligned", "10.0.0.1-6", []string{"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/31", "10.0.0.6/32"}},
+		{"whole ipv4", "0.0.0.0/0", []string{"0.0.0.0/0"}},
+		{"whole ipv6", "::/0", []string{"::/0"}},
+		{"ipv6 range", "2001:db8::-2001:db8::3", []string{"2001:db8::/126"}},
+	}
+
+	for _, tt := range tests {
+		ranges := mustParseIPRanges(t, tt.rng)
+		var got []string
+		for _, prefix := range ranges[0].prefixes() {
+			got = append(got, prefix.String())
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIPRangesWriter(t *testing.T) {
+	program := func(handle string, include []string, exclude []string) scope.ProgramData {
+		pData := scope.ProgramData{Platform: "h1", Handle: handle}
+		for _, target := range include {
+			pData.InScope = append(pData.InScope, scope.ScopeElement{Target: target, Category: scope.CategoryCIDR})
+		}
+		for _, target := range exclude {
+			pData.OutOfScope = append(pData.OutOfScope, scope.ScopeElement{Target: target})
+		}
+		return pData
+	}
+
+	tests := []struct {
+		name       string
+		programs   []scope.ProgramData
+		outOfScope bool
+		want       string
+	}{
+		{
+			name:     "own exclusion",
+			programs: []scope.ProgramData{program("a", []string{"10.0.0.0/24"}, []string{"10.0.0.0/25"})},
+			want:     "10.0.0.128/25\n",
+		},
+		{
+			name: "exclusion of another program",
+			programs: []scope.ProgramData{
+				program("a", []string{"10.0.0.0/25"}, []string{"10.0.1.0/24"}),
+				program("b", []string{"10.0.0.128/25", "10.0.1.0-10.0.2.255"}, nil),
+			},
+			want: "10.0.0.0/24\n10.0.2.0/24\n",
+		},
+		{
+			name: "exclusion written after the include",
+			programs: []scope.ProgramData{
+				program("a", []string{"10.0.0.0/24"}, nil),
+				program("b", nil, []string{"10.0.0.1 (staging)"}),
+			},
+			want: "10.0.0.0/32\n10.0.0.2/31\n10.0.0.4/30\n10.0.0.8/29\n10.0.0.16/28\n10.0.0.32/27\n10.0.0.64/26\n10.0.0.128/25\n",
+		},
+		{
+			name: "exclude list",
+			programs: []scope.ProgramData{
+				program("a", []string{"10.0.0.0/24"}, []string{"10.0.0.0/25"}),
+				program("b", nil, []string{"10.0.0.128/25", "example.com"}),
+			},
+			outOfScope: true,
+			want:       "10.0.0.0/24\n",
+		},
+		{
+			name:     "non address targets",
+			programs: []scope.ProgramData{program("a", []string{"example.com", "10.0.0.1 example.com"}, nil)},
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		var b strings.Builder
+		w, _ := newIPRangesWriter(&b, Options{OutOfScope: tt.outOfScope})
+		for _, pData := range tt.programs {
+			if err := w.WriteProgram(pData); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, b.String(), tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+// jsonWriter prints a JSON array holding every program, in-scope and out-of-scope elements included
+type jsonWriter struct {
+	w        io.Writer
+	programs int
+}
+
+func newJSONWriter(w io.Writer, opts Options) (Writer, error) {
+	return &jsonWriter{w: w}, nil
+}
+
+func (j *jsonWriter) WriteProgram(pData scope.ProgramData) error {
+	// Empty lists are easier to deal with than nulls in jq
+	if pData.InScope == nil {
+		pData.InScope = []scope.ScopeElement{}
+	}
+	if pData.OutOfScope == nil {
+		pData.OutOfScope = []scope.ScopeElement{}
+	}
+
+	data, err := json.MarshalIndent(pData, "  ", "  ")
+	if err != nil {
+		return err
+	}
+
+	separator := ",\n  "
+	if j.programs == 0 {
+		separator = "[\n  "
+	}
+	j.programs++
+
+	_, err = io.WriteString(j.w, separator+string(data))
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	closing := "\n]\n"
+	if j.programs == 0 {
+		closing = "[]\n"
+	}
+	_, err := io.WriteString(j.w, closing)
+	return err
+}
+
+// target is a single scope element along with the program it belongs to
+type target struct {
+	Platform       string `json:"platform"`
+	Handle         string `json:"handle"`
+	ProgramName    string `json:"program_name"`
+	ProgramURL     string `json:"program_url"`
+	Private        bool   `json:"private"`
+	OffersBounties bool   `json:"offers_bounties"`
+	InScope        bool   `json:"in_scope"`
+	scope.ScopeElement
+}
+
+// jsonLinesWriter prints one JSON object per scope element
+type jsonLinesWriter struct {
+	encoder *json.Encoder
+	opts    Options
+}
+
+func newJSONLinesWriter(w io.Writer, opts Options) (Writer, error) {
+	return &jsonLinesWriter{encoder: json.NewEncoder(w), opts: opts}, nil
+}
+
+func (j *jsonLinesWriter) WriteProgram(pData scope.ProgramData) error {
+	for _, element := range elements(pData, j.opts) {
+		err := j.encoder.Encode(target{
+			Platform:       pData.Platform,
+			Handle:         pData.Handle,
+			ProgramName:    pData.Name,
+			ProgramURL:     pData.Url,
+			Private:        pData.Private,
+			OffersBounties: pData.OffersBounties,
+			InScope:        !j.opts.OutOfScope,
+			ScopeElement:   element,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *jsonLinesWriter) Close() error {
+	return nil
+}
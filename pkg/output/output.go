@@ -0,0 +1,120 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+var ErrInvalidFormat = errors.New("invalid output format")
+
+// Options controls what the writers print
+type Options struct {
+	// OutputFlags selects the fields printed by the text and tabular formats
+	OutputFlags string
+	// Delimiter separates fields in the text format
+	Delimiter string
+	// OutOfScope prints out-of-scope elements instead of in-scope ones
+	OutOfScope bool
+	// Dir, when set, makes formats that support it write a file per program in this directory
+	Dir string
+}
+
+// Writer writes programs in a given format as they are fetched
+type Writer interface {
+	WriteProgram(pData scope.ProgramData) error
+	// Close writes whatever the format needs after the last program. It does not close the underlying io.Writer.
+	Close() error
+}
+
+// ErrorWriter is implemented by writers keeping track of the programs that could not be fetched
+type ErrorWriter interface {
+	// WriteError records a program that could not be fetched, or a whole platform if handle is empty
+	WriteError(platform string, handle string, err error) error
+}
+
+type constructor func(w io.Writer, opts Options) (Writer, error)
+
+var formats = map[string]constructor{
+	"text":       newTextWriter,
+	"json":       newJSONWriter,
+	"jsonl":      newJSONLinesWriter,
+	"csv":        newCSVWriter,
+	"tsv":        newTSVWriter,
+	"burp":       newBurpWriter,
+	"urls":       newURLsWriter,
+	"ipranges":   newIPRangesWriter,
+	"apps":       newAppsWriter,
+	"apps-csv":   newAppsCSVWriter,
+	"repos":      newReposWriter,
+	"repos-json": newReposJSONWriter,
+	"contracts":  newContractsWriter,
+}
+
+// Formats returns the name of every supported format, sorted
+func Formats() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New returns a Writer printing programs to w in the given format
+func New(format string, w io.Writer, opts Options) (Writer, error) {
+	newWriter, ok := formats[format]
+	if !ok {
+		return nil, fmt.Errorf("%w %q, valid choices are: %s", ErrInvalidFormat, format, strings.Join(Formats(), ", "))
+	}
+	return newWriter(w, opts)
+}
+
+// elements returns the scope elements selected by opts
+func elements(pData scope.ProgramData, opts Options) []scope.ScopeElement {
+	if opts.OutOfScope {
+		return pData.OutOfScope
+	}
+	return pData.InScope
+}
+
+type multiWriter []Writer
+
+// Multi returns a Writer that passes every program to all writers
+func Multi(writers ...Writer) Writer {
+	return multiWriter(writers)
+}
+
+func (m multiWriter) WriteProgram(pData scope.ProgramData) error {
+	for _, w := range m {
+		if err := w.WriteProgram(pData); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m multiWriter) WriteError(platform string, handle string, err error) error {
+	for _, w := range m {
+		if ew, ok := w.(ErrorWriter); ok {
+			if err := ew.WriteError(platform, handle, err); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m multiWriter) Close() error {
+	var errs []error
+	for _, w := range m {
+		if err := w.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
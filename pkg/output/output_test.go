@@ -0,0 +1,160 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+// testPrograms are two programs, the first one with in-scope and out-of-scope elements
+func testPrograms() []scope.ProgramData {
+	return []scope.ProgramData{
+		{
+			Platform:       "h1",
+			Handle:         "program",
+			Name:           `Program, "Inc"`,
+			Url:            "https://hackerone.com/program",
+			OffersBounties: true,
+			MaxBounty:      1500.5,
+			InScope: []scope.ScopeElement{
+				{Target: "*.example.com", Category: scope.CategoryWildcard, Description: "Main\tsite, \"prod\"\nno DoS", BountyEligible: scope.Bool(true)},
+				{Target: "10.0.0.0/24", Category: scope.CategoryCIDR, BountyEligible: scope.Bool(false)},
+			},
+			OutOfScope: []scope.ScopeElement{{Target: "blog.example.com", Category: scope.CategoryURL}},
+		},
+		{Platform: "bc", Handle: "empty", Private: true},
+	}
+}
+
+// writeAll writes programs in format and returns the output
+func writeAll(t *testing.T, format string, opts Options, programs []scope.ProgramData) string {
+	t.Helper()
+	var b strings.Builder
+	w, err := New(format, &b, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pData := range programs {
+		if err := w.WriteProgram(pData); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestDelimitedWriters(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		opts   Options
+		want   string
+	}{
+		{
+			name:   "csv quoting",
+			format: "csv",
+			opts:   Options{OutputFlags: "tdNb"},
+			want: "target,description,program_name,bounty_eligible\n" +
+				"*.example.com,\"Main\tsite, \"\"prod\"\"\nno DoS\",\"Program, \"\"Inc\"\"\",true\n" +
+				"10.0.0.0/24,,\"Program, \"\"Inc\"\"\",false\n",
+		},
+		{
+			name:   "field order",
+			format: "csv",
+			opts:   Options{OutputFlags: "MPtc"},
+			want:   "max_bounty,platform,target,category\n1500.5,h1,*.example.com,wildcard\n1500.5,h1,10.0.0.0/24,cidr\n",
+		},
+		{
+			name:   "out of scope",
+			format: "csv",
+			opts:   Options{OutputFlags: "tbV", OutOfScope: true},
+			want:   "target,bounty_eligible,visibility\nblog.example.com,,public\n",
+		},
+		{
+			name:   "tsv",
+			format: "tsv",
+			opts:   Options{OutputFlags: "tcd"},
+			want:   "target\tcategory\tdescription\n*.example.com\twildcard\t\"Main\tsite, \"\"prod\"\"\nno DoS\"\n10.0.0.0/24\tcidr\t\n",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := writeAll(t, tt.format, tt.opts, testPrograms()); got != tt.want {
+			t.Errorf("%s:\ngot  %q\nwant %q", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := New("csv", &strings.Builder{}, Options{OutputFlags: "tx"}); !errors.Is(err, scope.ErrInvalidField) {
+		t.Errorf("invalid output flag: got error %v, want ErrInvalidField", err)
+	}
+}
+
+func TestJSONWriter(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		programs []scope.ProgramData
+		want     int
+	}{
+		{"no program", nil, 0},
+		{"programs", testPrograms(), 2},
+	} {
+		out := writeAll(t, "json", Options{OutputFlags: "t"}, tt.programs)
+
+		var programs []map[string]interface{}
+		if err := json.Unmarshal([]byte(out), &programs); err != nil {
+			t.Fatalf("%s: invalid JSON: %v\n%s", tt.name, err, out)
+		}
+		if programs == nil || len(programs) != tt.want {
+			t.Errorf("%s: got %d programs, want %d", tt.name, len(programs), tt.want)
+		}
+		for _, pData := range programs {
+			// Whole programs are printed, whatever the output flags and --oos
+			if _, ok := pData["in_scope"].([]interface{}); !ok {
+				t.Errorf("%s: in_scope is not a list in %v", tt.name, pData)
+			}
+			if _, ok := pData["out_of_scope"].([]interface{}); !ok {
+				t.Errorf("%s: out_of_scope is not a list in %v", tt.name, pData)
+			}
+		}
+	}
+}
+
+func TestJSONLinesWriter(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       Options
+		wantLines  int
+		wantTarget string
+		inScope    bool
+	}{
+		{"in scope", Options{}, 2, "*.example.com", true},
+		{"out of scope", Options{OutOfScope: true}, 1, "blog.example.com", false},
+	}
+
+	for _, tt := range tests {
+		out := writeAll(t, "jsonl", tt.opts, testPrograms())
+		lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+		if len(lines) != tt.wantLines {
+			t.Fatalf("%s: got %d lines, want %d:\n%s", tt.name, len(lines), tt.wantLines, out)
+		}
+
+		var first target
+		if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+			t.Fatalf("%s: invalid JSON line: %v", tt.name, err)
+		}
+		if first.Target != tt.wantTarget || first.InScope != tt.inScope || first.Platform != "h1" || first.Handle != "program" || first.ProgramName != `Program, "Inc"` || !first.OffersBounties {
+			t.Errorf("%s: unexpected line %+v", tt.name, first)
+		}
+	}
+}
+
+func TestNewInvalidFormat(t *testing.T) {
+	if _, err := New("xml", &strings.Builder{}, Options{}); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("got error %v, want ErrInvalidFormat", err)
+	}
+}
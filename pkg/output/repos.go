@@ -0,0 +1,173 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+// Repository hosts, as found in URLs
+const (
+	HOST_GITHUB    = "github.com"
+	HOST_GITLAB    = "gitlab.com"
+	HOST_BITBUCKET = "bitbucket.org"
+)
+
+// repoURLRegex matches repository links. The host must not follow another host name character,
+// so that e.g. gist.github.com or notgithub.com links are not mistaken for repositories.
+var repoURLRegex = regexp.MustCompile(`(?i)(?:^|[^A-Za-z0-9.-])(?:https?://)?(?:www\.)?(github\.com|gitlab\.com|bitbucket\.org)((?:/[A-Za-z0-9_.~%-]+)+)`)
+
+// Path segments that follow the host without being an owner
+var reservedOwners = map[string]bool{
+	"about": true, "apps": true, "collections": true, "enterprise": true, "explore": true, "features": true,
+	"login": true, "marketplace": true, "orgs": true, "pricing": true, "settings": true, "sponsors": true,
+	"topics": true, "users": true, "dashboard": true, "help": true, "search": true,
+}
+
+// repo is a source code repository, along with the branch and path the program points to
+type repo struct {
+	Host     string   `json:"host"`
+	Owner    string   `json:"owner"`
+	Name     string   `json:"repo"`
+	Branch   string   `json:"branch,omitempty"`
+	Path     string   `json:"path,omitempty"`
+	CloneURL string   `json:"clone_url"`
+	Programs []string `json:"programs"`
+}
+
+// reposWriter prints the repositories found in scope targets and descriptions: their clone
+// URLs, one per line, or JSON records with their details
+type reposWriter struct {
+	w     io.Writer
+	opts  Options
+	json  bool
+	repos []*repo
+	seen  map[string]*repo
+}
+
+func newReposWriter(w io.Writer, opts Options) (Writer, error) {
+	return &reposWriter{w: w, opts: opts, seen: make(map[string]*repo)}, nil
+}
+
+func newReposJSONWriter(w io.Writer, opts Options) (Writer, error) {
+	return &reposWriter{w: w, opts: opts, json: true, seen: make(map[string]*repo)}, nil
+}
+
+func (r *reposWriter) WriteProgram(pData scope.ProgramData) error {
+	program := pData.Platform + ":" + pData.Handle
+	for _, element := range elements(pData, r.opts) {
+		for _, text := range []string{element.Target, element.Description} {
+			for _, found := range findRepos(text) {
+				found := found
+				// Clone URLs are printed once, whatever branch or path they come with
+				key := found.CloneURL
+				if r.json {
+					key += "|" + found.Branch + "|" + found.Path
+				}
+
+				existing, ok := r.seen[key]
+				if !ok {
+					existing = &found
+					r.seen[key] = existing
+					r.repos = append(r.repos, existing)
+
+					if !r.json {
+						if _, err := fmt.Fprintln(r.w, found.CloneURL); err != nil {
+							return err
+						}
+					}
+				}
+				if len(existing.Programs) == 0 || existing.Programs[len(existing.Programs)-1] != program {
+					existing.Programs = append(existing.Programs, program)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (r *reposWriter) Close() error {
+	if !r.json {
+		return nil
+	}
+
+	repos := r.repos
+	if repos == nil {
+		repos = []*repo{}
+	}
+	encoder := json.NewEncoder(r.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(repos)
+}
+
+// findRepos returns the GitHub, GitLab and Bitbucket repositories linked in text
+func findRepos(text string) []repo {
+	var repos []repo
+	for _, m := range repoURLRegex.FindAllStringSubmatch(text, -1) {
+		// Links often end a sentence
+		path := strings.Trim(strings.TrimRight(m[2], "."), "/")
+		if r, ok := parseRepo(strings.ToLower(m[1]), strings.Split(path, "/")); ok {
+			repos = append(repos, r)
+		}
+	}
+	return repos
+}
+
+// parseRepo reads a repository out of the path segments of its URL
+func parseRepo(host string, segments []string) (repo, bool) {
+	// Marks the end of the repository path, followed by the branch and the path within the repository
+	refMarkers := map[string]bool{"tree": true, "blob": true}
+	var namespace, ref []string
+
+	switch host {
+	case HOST_GITLAB:
+		// GitLab allows nested groups, so the repository ends where the "-" segment starts
+		for i, segment := range segments {
+			if segment == "-" {
+				namespace = segments[:i]
+				if i+1 < len(segments) && refMarkers[segments[i+1]] {
+					ref = segments[i+2:]
+				}
+				break
+			}
+		}
+		if namespace == nil {
+			namespace = segments
+		}
+	case HOST_BITBUCKET:
+		refMarkers = map[string]bool{"src": true}
+		fallthrough
+	default:
+		if len(segments) >= 2 {
+			namespace = segments[:2]
+		}
+		if len(segments) > 3 && refMarkers[segments[2]] {
+			ref = segments[3:]
+		}
+	}
+
+	if len(namespace) < 2 || reservedOwners[strings.ToLower(namespace[0])] {
+		return repo{}, false
+	}
+
+	name := strings.TrimSuffix(namespace[len(namespace)-1], ".git")
+	if name == "" {
+		return repo{}, false
+	}
+
+	r := repo{
+		Host:  host,
+		Owner: strings.Join(namespace[:len(namespace)-1], "/"),
+		Name:  name,
+	}
+	r.CloneURL = "https://" + r.Host + "/" + r.Owner + "/" + r.Name + ".git"
+	if len(ref) > 0 {
+		r.Branch = ref[0]
+		r.Path = strings.Join(ref[1:], "/")
+	}
+	return r, true
+}
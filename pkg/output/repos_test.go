@@ -0,0 +1,108 @@
+package output
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindRepos(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []repo
+	}{
+		{
+			name: "github",
+			text: "https://github.com/Owner/Repo",
+			want: []repo{{Host: HOST_GITHUB, Owner: "Owner", Name: "Repo", CloneURL: "https://github.com/Owner/Repo.git"}},
+		},
+		{
+			name: "no scheme and www",
+			text: "see www.github.com/owner/repo.git for details",
+			want: []repo{{Host: HOST_GITHUB, Owner: "owner", Name: "repo", CloneURL: "https://github.com/owner/repo.git"}},
+		},
+		{
+			name: "github tree",
+			text: "https://github.com/owner/repo/tree/main/contracts/src",
+			want: []repo{{Host: HOST_GITHUB, Owner: "owner", Name: "repo", Branch: "main", Path: "contracts/src", CloneURL: "https://github.com/owner/repo.git"}},
+		},
+		{
+			name: "github blob",
+			text: "https://github.com/owner/repo/blob/v1.2/README.md",
+			want: []repo{{Host: HOST_GITHUB, Owner: "owner", Name: "repo", Branch: "v1.2", Path: "README.md", CloneURL: "https://github.com/owner/repo.git"}},
+		},
+		{
+			name: "gitlab subgroups",
+			text: "https://gitlab.com/group/sub/project/-/tree/develop/lib",
+			want: []repo{{Host: HOST_GITLAB, Owner: "group/sub", Name: "project", Branch: "develop", Path: "lib", CloneURL: "https://gitlab.com/group/sub/project.git"}},
+		},
+		{
+			name: "gitlab subgroups without ref",
+			text: "https://gitlab.com/group/sub/project",
+			want: []repo{{Host: HOST_GITLAB, Owner: "group/sub", Name: "project", CloneURL: "https://gitlab.com/group/sub/project.git"}},
+		},
+		{
+			name: "bitbucket src",
+			text: "https://bitbucket.org/owner/repo/src/master/lib/a.go",
+			want: []repo{{Host: HOST_BITBUCKET, Owner: "owner", Name: "repo", Branch: "master", Path: "lib/a.go", CloneURL: "https://bitbucket.org/owner/repo.git"}},
+		},
+		{
+			name: "trailing punctuation",
+			text: "Code is at https://github.com/owner/repo. Also (https://gitlab.com/g/p), and https://github.com/o/r/.",
+			want: []repo{
+				{Host: HOST_GITHUB, Owner: "owner", Name: "repo", CloneURL: "https://github.com/owner/repo.git"},
+				{Host: HOST_GITLAB, Owner: "g", Name: "p", CloneURL: "https://gitlab.com/g/p.git"},
+				{Host: HOST_GITHUB, Owner: "o", Name: "r", CloneURL: "https://github.com/o/r.git"},
+			},
+		},
+		{
+			name: "adjacent links",
+			text: "github.com/a/b,github.com/c/d",
+			want: []repo{
+				{Host: HOST_GITHUB, Owner: "a", Name: "b", CloneURL: "https://github.com/a/b.git"},
+				{Host: HOST_GITHUB, Owner: "c", Name: "d", CloneURL: "https://github.com/c/d.git"},
+			},
+		},
+		{name: "gist", text: "https://gist.github.com/user/0123456789abcdef"},
+		{name: "api", text: "https://api.github.com/repos/owner/repo"},
+		{name: "other host", text: "https://notgithub.com/owner/repo"},
+		{name: "longer host", text: "https://github.com.example.com/owner/repo"},
+		{name: "reserved owner", text: "https://github.com/orgs/owner"},
+		{name: "owner only", text: "https://github.com/owner"},
+		{name: "no link", text: "github.com is out of scope"},
+	}
+
+	for _, tt := range tests {
+		if got := findRepos(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseRepo(t *testing.T) {
+	tests := []struct {
+		host   string
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{HOST_GITHUB, "owner/repo/tree/main", "https://github.com/owner/repo.git main ", true},
+		{HOST_GITHUB, "owner/repo/issues/1", "https://github.com/owner/repo.git  ", true},
+		{HOST_GITHUB, "owner/.git", "", false},
+		{HOST_GITLAB, "a/b/c/d/-/blob/main/x/y", "https://gitlab.com/a/b/c/d.git main x/y", true},
+		{HOST_GITLAB, "group/-/tree/main", "", false},
+		{HOST_BITBUCKET, "owner/repo/tree/main", "https://bitbucket.org/owner/repo.git  ", true},
+	}
+
+	for _, tt := range tests {
+		r, ok := parseRepo(tt.host, strings.Split(tt.path, "/"))
+		got := ""
+		if ok {
+			got = r.CloneURL + " " + r.Branch + " " + r.Path
+		}
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("parseRepo(%q, %q) = %q, %v, want %q, %v", tt.host, tt.path, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package output
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"text/template"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+// Names of the optional templates printed before and after the elements of each program
+const (
+	TEMPLATE_HEADER = "header"
+	TEMPLATE_FOOTER = "footer"
+)
+
+// TemplateFuncs are the functions available to user provided templates
+var TemplateFuncs = template.FuncMap{
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"replace":    strings.ReplaceAll,
+	"trimPrefix": strings.TrimPrefix,
+	"trimSuffix": strings.TrimSuffix,
+	"contains":   strings.Contains,
+}
+
+// TemplateElement is the data a template is executed with, once per scope element.
+// Element fields can be used directly (e.g. {{.Target}}) and program fields
+// through Program (e.g. {{.Program.Name}}).
+type TemplateElement struct {
+	scope.ScopeElement
+	Program scope.ProgramData
+}
+
+// templateWriter renders every scope element with a user provided text/template
+type templateWriter struct {
+	w    io.Writer
+	tmpl *template.Template
+	opts Options
+}
+
+// NewTemplateWriter returns a Writer rendering each scope element with text, a
+// text/template executed with a TemplateElement. If text defines templates named
+// "header" or "footer", they are executed with the ProgramData before and after
+// the elements of each program.
+func NewTemplateWriter(w io.Writer, text string, opts Options) (Writer, error) {
+	tmpl, err := template.New("element").Funcs(TemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return &templateWriter{w: w, tmpl: tmpl, opts: opts}, nil
+}
+
+func (t *templateWriter) WriteProgram(pData scope.ProgramData) error {
+	elements := elements(pData, t.opts)
+	if len(elements) == 0 {
+		return nil
+	}
+
+	if err := t.execute(TEMPLATE_HEADER, pData); err != nil {
+		return err
+	}
+	for _, element := range elements {
+		if err := t.execute("element", TemplateElement{ScopeElement: element, Program: pData}); err != nil {
+			return err
+		}
+	}
+	return t.execute(TEMPLATE_FOOTER, pData)
+}
+
+// execute renders the named template, if defined, ending its output with a single newline.
+// Trailing newlines are trimmed so that header and footer definitions can follow the
+// element template in a template file.
+func (t *templateWriter) execute(name string, data interface{}) error {
+	tmpl := t.tmpl.Lookup(name)
+	if tmpl == nil {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	out := bytes.TrimRight(buf.Bytes(), "\n")
+	if len(out) == 0 {
+		return nil
+	}
+
+	_, err := t.w.Write(append(out, '\n'))
+	return err
+}
+
+func (t *templateWriter) Close() error {
+	return nil
+}
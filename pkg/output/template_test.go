@@ -0,0 +1,74 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTemplateWriter(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		opts Options
+		want string
+	}{
+		{
+			name: "element",
+			text: "{{.Program.Platform}}/{{.Program.Handle}} {{.Target}} {{.Category}}",
+			want: "h1/program *.example.com wildcard\nh1/program 10.0.0.0/24 cidr\n",
+		},
+		{
+			name: "functions",
+			text: `{{trimPrefix .Target "*." | upper}}{{if contains .Target "/"}} {{replace .Target "/" "_"}}{{end}}`,
+			want: "EXAMPLE.COM\n10.0.0.0/24 10.0.0.0_24\n",
+		},
+		{
+			name: "header and footer",
+			text: "- {{.Target}}\n" +
+				`{{define "header"}}# {{.Name}}{{end}}` + "\n" +
+				`{{define "footer"}}{{len .InScope}} target(s)` + "\n\n{{end}}\n",
+			want: "# Program, \"Inc\"\n- *.example.com\n- 10.0.0.0/24\n2 target(s)\n",
+		},
+		{
+			name: "out of scope",
+			text: "{{.Target}} {{.BountyEligibility}}",
+			opts: Options{OutOfScope: true},
+			want: "blog.example.com \n",
+		},
+		{
+			name: "empty lines skipped",
+			text: `{{if eq .Category "cidr"}}{{.Target}}{{end}}`,
+			want: "10.0.0.0/24\n",
+		},
+	}
+
+	for _, tt := range tests {
+		var b strings.Builder
+		w, err := NewTemplateWriter(&b, tt.text, tt.opts)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		for _, pData := range testPrograms() {
+			if err := w.WriteProgram(pData); err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+		}
+		if got := b.String(); got != tt.want {
+			t.Errorf("%s:\ngot  %q\nwant %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTemplateWriterErrors(t *testing.T) {
+	if _, err := NewTemplateWriter(&strings.Builder{}, "{{.Target", Options{}); err == nil {
+		t.Error("parse error: got no error")
+	}
+
+	w, err := NewTemplateWriter(&strings.Builder{}, "{{.Missing}}", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteProgram(testPrograms()[0]); err == nil {
+		t.Error("execution error: got no error")
+	}
+}
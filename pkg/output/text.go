@@ -0,0 +1,31 @@
+package output
+
+import (
+	"io"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+// textWriter prints the fields selected by the output flags, joined by the delimiter
+type textWriter struct {
+	w    io.Writer
+	opts Options
+}
+
+func newTextWriter(w io.Writer, opts Options) (Writer, error) {
+	if _, err := scope.ParseFields(opts.OutputFlags); err != nil {
+		return nil, err
+	}
+	return &textWriter{w: w, opts: opts}, nil
+}
+
+func (t *textWriter) WriteProgram(pData scope.ProgramData) error {
+	if t.opts.OutOfScope {
+		return scope.FprintProgramOutOfScope(t.w, pData, t.opts.OutputFlags, t.opts.Delimiter)
+	}
+	return scope.FprintProgramScope(t.w, pData, t.opts.OutputFlags, t.opts.Delimiter)
+}
+
+func (t *textWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,77 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+// urlsWriter prints the web targets as absolute URLs, one per line, without duplicates
+type urlsWriter struct {
+	w    io.Writer
+	opts Options
+	seen map[string]bool
+}
+
+func newURLsWriter(w io.Writer, opts Options) (Writer, error) {
+	return &urlsWriter{w: w, opts: opts, seen: make(map[string]bool)}, nil
+}
+
+func (u *urlsWriter) WriteProgram(pData scope.ProgramData) error {
+	for _, t := range webTargets(elements(pData, u.opts), !u.opts.OutOfScope) {
+		for _, url := range targetURLs(t) {
+			if u.seen[url] {
+				continue
+			}
+			u.seen[url] = true
+			if _, err := fmt.Fprintln(u.w, url); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (u *urlsWriter) Close() error {
+	return nil
+}
+
+// targetURLs returns the URLs to probe for a target, keeping its path up to the first wildcard.
+// Wildcards are reduced to the host they are based on (e.g. "*.example.com" becomes "example.com"),
+// and both http and https URLs are returned unless the scheme or the port tell which one to use.
+func targetURLs(t webTarget) []string {
+	host := t.Host
+	if i := strings.LastIndex(host, "*"); i >= 0 {
+		host = strings.TrimLeft(host[i+1:], ".-")
+		if !strings.Contains(host, ".") {
+			return nil
+		}
+	}
+
+	path := t.Path
+	if i := strings.Index(path, "*"); i >= 0 {
+		path = path[:i]
+	}
+
+	schemes := []string{"http", "https"}
+	switch {
+	case t.Scheme != "":
+		schemes = []string{t.Scheme}
+	case t.Port == "80":
+		schemes = []string{"http"}
+	case t.Port == "443":
+		schemes = []string{"https"}
+	}
+
+	var urls []string
+	for _, scheme := range schemes {
+		url := scheme + "://" + host
+		if t.Port != "" && !(scheme == "http" && t.Port == "80") && !(scheme == "https" && t.Port == "443") {
+			url += ":" + t.Port
+		}
+		urls = append(urls, url+path)
+	}
+	return urls
+}
@@ -0,0 +1,110 @@
+package output
+
+import (
+	"net/netip"
+	"regexp"
+	"strings"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+var (
+	hostRegex = regexp.MustCompile(`^(?:[a-z0-9*_-]+\.)+[a-z0-9*_-]+$`)
+	portRegex = regexp.MustCompile(`^[0-9]{1,5}$`)
+)
+
+// webTarget is a scope target split into the parts scanners and proxies care about
+type webTarget struct {
+	// Scheme is "http", "https" or empty when the target doesn't tell
+	Scheme string
+	// Host is lower case and may contain wildcards (e.g. "*.example.com")
+	Host string
+	// Port is empty when the target doesn't tell
+	Port string
+	// Path starts with a slash, or is empty. It may contain wildcards.
+	Path string
+}
+
+// parseWebTarget extracts the host, and scheme, port and path if present, from
+// targets such as "*.example.com", "example.com:8443" or "https://example.com/api/*".
+// It reports false for targets that are not a host name or URL, such as CIDRs.
+func parseWebTarget(target string) (webTarget, bool) {
+	var t webTarget
+	rest := strings.TrimSpace(target)
+
+	// "10.0.0.0/24" would otherwise read as a host and a path
+	if _, err := netip.ParsePrefix(rest); err == nil {
+		return t, false
+	}
+
+	if i := strings.Index(rest, "://"); i >= 0 {
+		t.Scheme = strings.ToLower(rest[:i])
+		if t.Scheme != "http" && t.Scheme != "https" {
+			return t, false
+		}
+		rest = rest[i+3:]
+	}
+
+	// Queries and fragments don't change what's in scope
+	if i := strings.IndexAny(rest, "?#"); i >= 0 {
+		rest = rest[:i]
+	}
+
+	if i := strings.Index(rest, "/"); i >= 0 {
+		t.Path = rest[i:]
+		rest = rest[:i]
+	}
+
+	if i := strings.LastIndex(rest, ":"); i >= 0 {
+		port := rest[i+1:]
+		if port != "*" && !portRegex.MatchString(port) {
+			return t, false
+		}
+		if port != "*" {
+			t.Port = port
+		}
+		rest = rest[:i]
+	}
+
+	t.Host = strings.TrimSuffix(strings.ToLower(rest), ".")
+	if !hostRegex.MatchString(t.Host) {
+		return t, false
+	}
+
+	if t.Path == "/" || t.Path == "/*" {
+		t.Path = ""
+	}
+
+	return t, true
+}
+
+// webTargets returns the elements that are hosts or URLs, parsed. In-scope elements must
+// belong to a web category, while out-of-scope ones may also be uncategorized, since
+// programs don't always bother categorizing what's excluded. The raw asset identifier is
+// preferred over the host name mined out of it, as it keeps wildcards, schemes, ports and paths.
+func webTargets(elements []scope.ScopeElement, inScope bool) []webTarget {
+	var targets []webTarget
+	for _, element := range elements {
+		if !isWebCategory(element.Category) && (inScope || element.Category != scope.CategoryOther) {
+			continue
+		}
+		t, ok := parseWebTarget(element.RawTarget)
+		if !ok {
+			t, ok = parseWebTarget(element.Target)
+		}
+		if ok {
+			targets = append(targets, t)
+		}
+	}
+	return targets
+}
+
+// isWebCategory reports whether elements of the category are expected to be hosts or URLs
+func isWebCategory(category string) bool {
+	return category == scope.CategoryURL || category == scope.CategoryWildcard || category == scope.CategoryAPI
+}
+
+// wildcardRegex quotes s for use in a regular expression, turning each * into .*
+func wildcardRegex(s string) string {
+	return strings.ReplaceAll(regexp.QuoteMeta(s), `\*`, `.*`)
+}
@@ -0,0 +1,253 @@
+package output
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+func TestParseWebTarget(t *testing.T) {
+	tests := []struct {
+		target string
+		want   webTarget
+		wantOK bool
+	}{
+		{"example.com", webTarget{Host: "example.com"}, true},
+		{"*.Example.com.", webTarget{Host: "*.example.com"}, true},
+		{"host.example.com:8443", webTarget{Host: "host.example.com", Port: "8443"}, true},
+		{"example.com:*", webTarget{Host: "example.com"}, true},
+		{"https://host.example.com/api/*", webTarget{Scheme: "https", Host: "host.example.com", Path: "/api/*"}, true},
+		{"HTTP://example.com/?q=1#top", webTarget{Scheme: "http", Host: "example.com"}, true},
+		{"https://example.com:8443/a/b", webTarget{Scheme: "https", Host: "example.com", Port: "8443", Path: "/a/b"}, true},
+		{"10.0.0.1", webTarget{Host: "10.0.0.1"}, true},
+		{"http://10.0.0.1:8080/", webTarget{Scheme: "http", Host: "10.0.0.1", Port: "8080"}, true},
+		{"10.0.0.0/24", webTarget{}, false},
+		{"2001:db8::/32", webTarget{}, false},
+		{"ftp://example.com", webTarget{}, false},
+		{"example.com:http", webTarget{}, false},
+		{"com.example.app", webTarget{Host: "com.example.app"}, true},
+		{"Example app (iOS)", webTarget{}, false},
+		{"", webTarget{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseWebTarget(tt.target)
+		if ok != tt.wantOK || (ok && got != tt.want) {
+			t.Errorf("parseWebTarget(%q) = %+v, %v, want %+v, %v", tt.target, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestWebTargets(t *testing.T) {
+	elements := []scope.ScopeElement{
+		{Target: "example.com", RawTarget: "*.example.com", Category: scope.CategoryWildcard},
+		{Target: "api.example.com", Category: scope.CategoryAPI},
+		{Target: "10.0.0.0/24", Category: scope.CategoryCIDR},
+		{Target: "legacy.example.com", Category: scope.CategoryOther},
+		{Target: "shop.example.com", RawTarget: "Shop (shop.example.com)", Category: scope.CategoryURL},
+	}
+
+	tests := []struct {
+		inScope bool
+		want    []string
+	}{
+		{true, []string{"*.example.com", "api.example.com", "shop.example.com"}},
+		{false, []string{"*.example.com", "api.example.com", "legacy.example.com", "shop.example.com"}},
+	}
+
+	for _, tt := range tests {
+		var got []string
+		for _, target := range webTargets(elements, tt.inScope) {
+			got = append(got, target.Host)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("webTargets(inScope=%v) = %v, want %v", tt.inScope, got, tt.want)
+		}
+	}
+}
+
+func TestBurpWriter(t *testing.T) {
+	pData := scope.ProgramData{
+		Platform: "h1",
+		Handle:   "program",
+		InScope: []scope.ScopeElement{
+			{Target: "example.com", RawTarget: "*.example.com", Category: scope.CategoryWildcard},
+			{Target: "host.example.com", RawTarget: "host.example.com:8443", Category: scope.CategoryURL},
+			{Target: "host.example.com", RawTarget: "https://host.example.com/api/*", Category: scope.CategoryAPI},
+			{Target: "10.0.0.1", Category: scope.CategoryURL},
+			{Target: "10.0.0.0/24", Category: scope.CategoryCIDR},
+		},
+		OutOfScope: []scope.ScopeElement{
+			{Target: "admin.example.com", Category: scope.CategoryURL},
+			{Target: "10.0.1.0/24", Category: scope.CategoryOther},
+		},
+	}
+
+	var b strings.Builder
+	w, _ := newBurpWriter(&b, Options{})
+	if err := w.WriteProgram(pData); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var options burpProjectOptions
+	if err := json.Unmarshal([]byte(b.String()), &options); err != nil {
+		t.Fatal(err)
+	}
+
+	wantInclude := []burpRule{
+		{Enabled: true, Protocol: "any", Host: `^.*\.example\.com$`},
+		{Enabled: true, Protocol: "any", Host: `^host\.example\.com$`, Port: "^8443$"},
+		{Enabled: true, Protocol: "https", Host: `^host\.example\.com$`, File: `^/api/.*`},
+		{Enabled: true, Protocol: "any", Host: `^10\.0\.0\.1$`},
+	}
+	wantExclude := []burpRule{
+		{Enabled: true, Protocol: "any", Host: `^admin\.example\.com$`},
+	}
+	if !reflect.DeepEqual(options.Target.Scope.Include, wantInclude) {
+		t.Errorf("include rules:\ngot  %+v\nwant %+v", options.Target.Scope.Include, wantInclude)
+	}
+	if !reflect.DeepEqual(options.Target.Scope.Exclude, wantExclude) {
+		t.Errorf("exclude rules:\ngot  %+v\nwant %+v", options.Target.Scope.Exclude, wantExclude)
+	}
+
+	// Burp applies exclude rules first, the excluded subdomain must match both
+	for _, rule := range []burpRule{wantInclude[0], wantExclude[0]} {
+		if !regexp.MustCompile(rule.Host).MatchString("admin.example.com") {
+			t.Errorf("%s does not match admin.example.com", rule.Host)
+		}
+	}
+}
+
+func TestZAPRegex(t *testing.T) {
+	tests := []struct {
+		target  string
+		match   []string
+		noMatch []string
+	}{
+		{
+			target:  "*.example.com",
+			match:   []string{"https://www.example.com", "http://a.b.example.com:8080/path?q=1", "https://www.example.com#top"},
+			noMatch: []string{"https://example.com", "https://evil.com/.example.com", "https://www.example.com.evil.com/", "ftp://www.example.com"},
+		},
+		{
+			target:  "host.example.com:8443",
+			match:   []string{"https://host.example.com:8443/", "http://host.example.com:8443"},
+			noMatch: []string{"https://host.example.com/", "https://host.example.com:8444/"},
+		},
+		{
+			target:  "https://host.example.com/api/*",
+			match:   []string{"https://host.example.com/api/", "https://host.example.com/api/v1/users"},
+			noMatch: []string{"http://host.example.com/api/", "https://host.example.com/apiv1", "https://host.example.com/"},
+		},
+		{
+			target:  "10.0.0.1",
+			match:   []string{"http://10.0.0.1", "https://10.0.0.1:8443/login"},
+			noMatch: []string{"http://10.0.0.10", "http://10a0b0c1"},
+		},
+	}
+
+	for _, tt := range tests {
+		target, ok := parseWebTarget(tt.target)
+		if !ok {
+			t.Fatalf("parseWebTarget(%q) failed", tt.target)
+		}
+		// ZAP matches regexes against whole URLs
+		re := regexp.MustCompile("^(?:" + zapRegex(target) + ")$")
+		for _, url := range tt.match {
+			if !re.MatchString(url) {
+				t.Errorf("%s: %s does not match %s", tt.target, re, url)
+			}
+		}
+		for _, url := range tt.noMatch {
+			if re.MatchString(url) {
+				t.Errorf("%s: %s matches %s", tt.target, re, url)
+			}
+		}
+	}
+}
+
+func TestZAPRegexesWildcardWithExclusion(t *testing.T) {
+	include := zapRegexes(webTargets([]scope.ScopeElement{
+		{Target: "example.com", RawTarget: "*.example.com", Category: scope.CategoryWildcard},
+		{Target: "example.com", RawTarget: "*.example.com", Category: scope.CategoryWildcard},
+	}, true))
+	exclude := zapRegexes(webTargets([]scope.ScopeElement{{Target: "admin.example.com", Category: scope.CategoryURL}}, false))
+	if len(include) != 1 || len(exclude) != 1 {
+		t.Fatalf("got include %v and exclude %v, want one regex each", include, exclude)
+	}
+
+	url := "https://admin.example.com/login"
+	for _, regex := range []string{include[0], exclude[0]} {
+		if !regexp.MustCompile("^(?:" + regex + ")$").MatchString(url) {
+			t.Errorf("%s does not match %s", regex, url)
+		}
+	}
+	if regexp.MustCompile("^(?:" + exclude[0] + ")$").MatchString("https://www.example.com/") {
+		t.Errorf("%s excludes more than the subdomain", exclude[0])
+	}
+}
+
+func TestTargetURLs(t *testing.T) {
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{"example.com", []string{"http://example.com", "https://example.com"}},
+		{"*.example.com", []string{"http://example.com", "https://example.com"}},
+		{"*.com", nil},
+		{"host.example.com:8443", []string{"http://host.example.com:8443", "https://host.example.com:8443"}},
+		{"host.example.com:443", []string{"https://host.example.com"}},
+		{"host.example.com:80", []string{"http://host.example.com"}},
+		{"https://host.example.com/api/*", []string{"https://host.example.com/api/"}},
+		{"http://host.example.com:8080/app/v*/docs", []string{"http://host.example.com:8080/app/v"}},
+		{"10.0.0.1", []string{"http://10.0.0.1", "https://10.0.0.1"}},
+	}
+
+	for _, tt := range tests {
+		target, ok := parseWebTarget(tt.target)
+		if !ok {
+			t.Fatalf("parseWebTarget(%q) failed", tt.target)
+		}
+		if got := targetURLs(target); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("targetURLs(%q) = %v, want %v", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestURLsWriter(t *testing.T) {
+	pData := scope.ProgramData{
+		InScope: []scope.ScopeElement{
+			{Target: "example.com", RawTarget: "*.example.com", Category: scope.CategoryWildcard},
+			{Target: "example.com", Category: scope.CategoryURL},
+			{Target: "10.0.0.0/24", Category: scope.CategoryCIDR},
+			{Target: "com.example.app", Category: scope.CategoryAndroid},
+		},
+		OutOfScope: []scope.ScopeElement{{Target: "admin.example.com", Category: scope.CategoryURL}},
+	}
+
+	tests := []struct {
+		outOfScope bool
+		want       string
+	}{
+		{false, "http://example.com\nhttps://example.com\n"},
+		{true, "http://admin.example.com\nhttps://admin.example.com\n"},
+	}
+
+	for _, tt := range tests {
+		var b strings.Builder
+		w, _ := newURLsWriter(&b, Options{OutOfScope: tt.outOfScope})
+		if err := w.WriteProgram(pData); err != nil {
+			t.Fatal(err)
+		}
+		if b.String() != tt.want {
+			t.Errorf("oos=%v: got %q, want %q", tt.outOfScope, b.String(), tt.want)
+		}
+	}
+}
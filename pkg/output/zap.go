@@ -0,0 +1,108 @@
+package output
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+type zapContext struct {
+	Name        string   `xml:"name"`
+	Description string   `xml:"desc"`
+	InScope     bool     `xml:"inscope"`
+	Include     []string `xml:"incregexes"`
+	Exclude     []string `xml:"excregexes"`
+}
+
+// zapConfiguration is the root element of a ZAP context file
+type zapConfiguration struct {
+	XMLName xml.Name   `xml:"configuration"`
+	Context zapContext `xml:"context"`
+}
+
+// zapWriter writes a ZAP context file per program, named after the program handle, with
+// include regexes built from the in-scope web targets and exclude regexes from the out-of-scope ones
+type zapWriter struct {
+	dir string
+}
+
+// NewZAPWriter returns a Writer saving a ZAP context file for each program in dir
+func NewZAPWriter(dir string) Writer {
+	return &zapWriter{dir: dir}
+}
+
+func (z *zapWriter) WriteProgram(pData scope.ProgramData) error {
+	context := zapContext{
+		Name:        pData.Handle,
+		Description: strings.TrimSpace(pData.Name + " " + pData.Url),
+		InScope:     true,
+		Include:     zapRegexes(webTargets(pData.InScope, true)),
+		Exclude:     zapRegexes(webTargets(pData.OutOfScope, false)),
+	}
+	if len(context.Include) == 0 {
+		return nil
+	}
+
+	f, err := os.Create(filepath.Join(z.dir, programFileName(pData, ".context")))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := io.WriteString(f, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(f)
+	encoder.Indent("", "    ")
+	if err := encoder.Encode(zapConfiguration{Context: context}); err != nil {
+		return err
+	}
+	_, err = io.WriteString(f, "\n")
+	return err
+}
+
+func (z *zapWriter) Close() error {
+	return nil
+}
+
+// zapRegexes returns deduplicated regexes matching the whole URLs of the given targets
+func zapRegexes(targets []webTarget) []string {
+	var regexes []string
+	seen := make(map[string]bool)
+	for _, t := range targets {
+		regex := zapRegex(t)
+		if !seen[regex] {
+			seen[regex] = true
+			regexes = append(regexes, regex)
+		}
+	}
+	return regexes
+}
+
+func zapRegex(t webTarget) string {
+	regex := "https?"
+	if t.Scheme != "" {
+		regex = t.Scheme
+	}
+
+	// Unlike Burp, ZAP matches whole URLs: wildcards in the host must not go past it
+	regex += "://" + strings.ReplaceAll(regexp.QuoteMeta(t.Host), `\*`, `[^/:]*`)
+
+	if t.Port != "" {
+		regex += ":" + t.Port
+	} else {
+		regex += "(?::[0-9]+)?"
+	}
+
+	if t.Path != "" {
+		regex += wildcardRegex(strings.TrimSuffix(t.Path, "*")) + ".*"
+	} else {
+		regex += "(?:[/?#].*)?"
+	}
+	return regex
+}
@@ -2,29 +2,36 @@ package bugcrowd
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/sw33tLie/bbscope/internal/utils"
+	"github.com/sw33tLie/bbscope/pkg/platforms"
 	"github.com/sw33tLie/bbscope/pkg/scope"
 	"github.com/sw33tLie/bbscope/pkg/whttp"
 	"github.com/tidwall/gjson"
 )
 
 const (
+	PLATFORM_NAME            = "bc"
+	BUGCROWD_BASE_URL        = "https://bugcrowd.com"
 	USER_AGENT               = "Mozilla/5.0 (X11; Linux x86_64; rv:82.0) Gecko/20100101 Firefox/82.0"
 	BUGCROWD_LOGIN_PAGE      = "https://bugcrowd.com/user/sign_in"
 	RATE_LIMIT_SLEEP_SECONDS = 5
+	RATE_LIMIT_MAX_RETRIES   = 50
 )
 
+var targetRegex = regexp.MustCompile("(?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\\.)+[a-z0-9][a-z0-9-]{0,61}[a-z0-9]")
+
 type Program struct {
 	Targets []struct {
 		ID          string `json:"id,omitempty"`
@@ -36,15 +43,15 @@ type Program struct {
 	} `json:"targets,omitempty"`
 }
 
-func Login(email string, password string) string {
+func Login(ctx context.Context, email string, password string) (string, error) {
 	// Send GET to https://bugcrowd.com/user/sign_in
 	// Get _crowdcontrol_session_key cookie
 	// Get <meta name="csrf-token" content="Da...ktOQ==" />
 	// Still under development
 
-	req, err := http.NewRequest("GET", BUGCROWD_LOGIN_PAGE, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", BUGCROWD_LOGIN_PAGE, nil)
 	if err != nil {
-		utils.Log.Fatal(err)
+		return "", err
 	}
 
 	req.Header.Set("User-Agent", USER_AGENT)
@@ -56,7 +63,7 @@ func Login(email string, password string) string {
 	}
 	resp, err := client.Do(req)
 	if err != nil {
-		panic(err)
+		return "", &platforms.Error{Platform: PLATFORM_NAME, Err: err}
 	}
 	defer resp.Body.Close()
 
@@ -70,7 +77,7 @@ func Login(email string, password string) string {
 	}
 
 	if crowdControlSession == "" {
-		utils.Log.Fatal("Failed to get cookie. Something might have changed")
+		return "", platforms.SchemaError(PLATFORM_NAME, "", errors.New("failed to get session cookie"))
 	}
 
 	// Now we need to get the csrf-token...HTML parsing here we go
@@ -79,7 +86,7 @@ func Login(email string, password string) string {
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(body)))
 
 	if err != nil {
-		utils.Log.Fatal("Failed to parse login response")
+		return "", platforms.SchemaError(PLATFORM_NAME, "", err)
 	}
 
 	doc.Find("meta").Each(func(index int, s *goquery.Selection) {
@@ -91,20 +98,20 @@ func Login(email string, password string) string {
 	})
 
 	if csrfToken == "" {
-		utils.Log.Fatal("Failed to get the CSRF token. Something might have changed")
+		return "", platforms.SchemaError(PLATFORM_NAME, "", errors.New("failed to get the CSRF token"))
 	}
 
 	// Now send the POST request
-	req2, err := http.NewRequest("POST", BUGCROWD_LOGIN_PAGE, bytes.NewBuffer([]byte("utf8=%E2%9C%93&authenticity_token="+url.QueryEscape(csrfToken)+"&user%5Bredirect_to%5D=&user%5Bemail%5D="+url.QueryEscape(email)+"&user%5Bpassword%5D="+url.QueryEscape(password)+"&commit=Log+in")))
+	req2, err := http.NewRequestWithContext(ctx, "POST", BUGCROWD_LOGIN_PAGE, bytes.NewBuffer([]byte("utf8=%E2%9C%93&authenticity_token="+url.QueryEscape(csrfToken)+"&user%5Bredirect_to%5D=&user%5Bemail%5D="+url.QueryEscape(email)+"&user%5Bpassword%5D="+url.QueryEscape(password)+"&commit=Log+in")))
 	if err != nil {
-		utils.Log.Fatal(err)
+		return "", err
 	}
 
 	req2.Header.Set("User-Agent", USER_AGENT)
 	req2.Header.Set("Cookie", "_crowdcontrol_session_key="+crowdControlSession)
 	resp2, err := client.Do(req2)
 	if err != nil {
-		panic(err)
+		return "", &platforms.Error{Platform: PLATFORM_NAME, Err: err}
 	}
 	defer resp2.Body.Close()
 
@@ -117,17 +124,59 @@ func Login(email string, password string) string {
 	}
 
 	if resp2.StatusCode != 302 {
-		utils.Log.Fatal("Login failed", resp2.StatusCode)
+		return "", &platforms.Error{Platform: PLATFORM_NAME, StatusCode: resp2.StatusCode, Err: platforms.ErrUnauthorized}
+	}
+
+	return sessionToken, nil
+}
+
+// sendRequest sends a GET request with the session cookie, retrying while rate limited
+func sendRequest(ctx context.Context, url string, token string, handle string) (*whttp.WHTTPRes, error) {
+	client := &http.Client{}
+
+	for i := 0; i < RATE_LIMIT_MAX_RETRIES; i++ {
+		res, err := whttp.SendHTTPRequest(
+			ctx,
+			&whttp.WHTTPReq{
+				Method: "GET",
+				URL:    url,
+				Headers: []whttp.WHTTPHeader{
+					{Name: "Cookie", Value: "_crowdcontrol_session_key=" + token},
+					{Name: "User-Agent", Value: USER_AGENT},
+					{Name: "Accept", Value: "*/*"},
+				},
+			}, client)
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, &platforms.Error{Platform: PLATFORM_NAME, Handle: handle, Err: err}
+		}
+
+		// Rate limiting retry
+		if res.StatusCode != 429 {
+			if err := platforms.StatusError(PLATFORM_NAME, handle, res.StatusCode); err != nil {
+				return nil, err
+			}
+			return res, nil
+		}
+
+		utils.Log.Warn("Hit rate limiting (429), retrying...")
+		if err := utils.Sleep(ctx, RATE_LIMIT_SLEEP_SECONDS*time.Second); err != nil {
+			return nil, err
+		}
 	}
 
-	return sessionToken
+	return nil, platforms.StatusError(PLATFORM_NAME, handle, 429)
 }
 
-func GetProgramHandles(sessionToken string, bbpOnly bool, pvtOnly bool) []string {
+// GetPrograms returns the programs matching the filters, with the metadata shown in the programs list
+func GetPrograms(ctx context.Context, sessionToken string, bbpOnly bool, pvtOnly bool) ([]scope.ProgramData, error) {
 	totalPages := 0
 	pageIndex := 1
 
-	listEndpointURL := "https://bugcrowd.com/programs.json?"
+	listEndpointURL := BUGCROWD_BASE_URL + "/programs.json?"
 	if pvtOnly {
 		listEndpointURL = listEndpointURL + "accepted_invite[]=true&"
 	}
@@ -135,45 +184,40 @@ func GetProgramHandles(sessionToken string, bbpOnly bool, pvtOnly bool) []string
 		listEndpointURL = listEndpointURL + "vdp[]=false&"
 	}
 	listEndpointURL = listEndpointURL + "hidden[]=false&sort[]=invited-desc&sort[]=promoted-desc&page[]="
-	paths := []string{}
+	programs := []scope.ProgramData{}
 
 	for {
-		var res *whttp.WHTTPRes
-		var err error
-
-		client := &http.Client{}
-
-		for {
-			res, err = whttp.SendHTTPRequest(
-				&whttp.WHTTPReq{
-					Method: "GET",
-					URL:    listEndpointURL + strconv.Itoa(pageIndex),
-					Headers: []whttp.WHTTPHeader{
-						{Name: "Cookie", Value: "_crowdcontrol_session_key=" + sessionToken},
-						{Name: "User-Agent", Value: USER_AGENT},
-					},
-				}, client)
-
-			if err != nil {
-				utils.Log.Fatal(err)
-			}
-
-			// Rate limiting retry
-			if res.StatusCode != 429 {
-				break
-			} else {
-				utils.Log.Warn("Hit rate limiting (429), retrying...")
-				time.Sleep(RATE_LIMIT_SLEEP_SECONDS * time.Second)
-			}
+		res, err := sendRequest(ctx, listEndpointURL+strconv.Itoa(pageIndex), sessionToken, "")
+		if err != nil {
+			return nil, err
 		}
 
 		if totalPages == 0 {
-			totalPages = int(gjson.Get(string(res.BodyString), "meta.totalPages").Int())
+			if !gjson.Get(res.BodyString, "meta.totalPages").Exists() {
+				return nil, platforms.SchemaError(PLATFORM_NAME, "", nil)
+			}
+			totalPages = int(gjson.Get(res.BodyString, "meta.totalPages").Int())
 		}
 
-		chunkData := gjson.Get(string(res.BodyString), "programs.#.program_url")
-		for i := 0; i < len(chunkData.Array()); i++ {
-			paths = append(paths, chunkData.Array()[i].Str)
+		for _, program := range gjson.Get(res.BodyString, "programs").Array() {
+			pData := scope.ProgramData{
+				Platform:  PLATFORM_NAME,
+				Handle:    strings.Trim(program.Get("program_url").Str, "/"),
+				Name:      program.Get("name").Str,
+				Url:       BUGCROWD_BASE_URL + program.Get("program_url").Str,
+				Private:   program.Get("participation").Str == "private",
+				MinBounty: program.Get("min_rewards").Float(),
+				MaxBounty: program.Get("max_rewards").Float(),
+			}
+			if program.Get("vdp").Exists() {
+				pData.OffersBounties = !program.Get("vdp").Bool()
+			} else {
+				pData.OffersBounties = pData.MaxBounty > 0
+			}
+			if pData.OffersBounties {
+				pData.Currency = "USD"
+			}
+			programs = append(programs, pData)
 		}
 
 		pageIndex++
@@ -184,188 +228,151 @@ func GetProgramHandles(sessionToken string, bbpOnly bool, pvtOnly bool) []string
 
 	}
 
-	return paths
+	return programs, nil
 }
 
-func GetProgramScope(handle string, categories string, token string) (pData scope.ProgramData) {
-	r, err := regexp.Compile("(?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\\.)+[a-z0-9][a-z0-9-]{0,61}[a-z0-9]")
+// GetProgramScope returns the scope of the program with the given handle (e.g. "tesla")
+func GetProgramScope(ctx context.Context, handle string, token string) (pData scope.ProgramData, err error) {
+	pData.Platform = PLATFORM_NAME
+	pData.Handle = handle
+	pData.Url = BUGCROWD_BASE_URL + "/" + handle
+
+	res, err := sendRequest(ctx, pData.Url+"/target_groups", token, handle)
 	if err != nil {
-		utils.Log.Fatal("Could not create regex pattern for handle ", handle)
+		return pData, err
 	}
-	pData.Url = "https://bugcrowd.com" + handle
-
-	var res, res2 *whttp.WHTTPRes
-
-	client := &http.Client{}
 
-	for {
-		res, err = whttp.SendHTTPRequest(
-			&whttp.WHTTPReq{
-				Method: "GET",
-				URL:    pData.Url + "/target_groups",
-				Headers: []whttp.WHTTPHeader{
-					{Name: "Cookie", Value: "_crowdcontrol_session_key=" + token},
-					{Name: "User-Agent", Value: USER_AGENT},
-					{Name: "Accept", Value: "*/*"},
-				},
-			}, client)
-
-		if err != nil {
-			utils.Log.Fatal(err)
-		}
+	// Times @arcwhite broke our code: #3 and counting :D
 
-		// Rate limiting retry
-		if res.StatusCode != 429 {
-			break
-		} else {
-			utils.Log.Warn("Hit rate limiting (429), retrying...")
-			time.Sleep(RATE_LIMIT_SLEEP_SECONDS * time.Second)
-		}
+	if !gjson.Get(res.BodyString, "groups").IsArray() {
+		return pData, platforms.SchemaError(PLATFORM_NAME, handle, nil)
 	}
 
-	// Times @arcwhite broke our code: #3 and counting :D
-
-	//noScopeTable := true
-	for _, scopeTableURL := range gjson.Get(string(res.BodyString), "groups.#(in_scope==true)#.targets_url").Array() {
+	for _, group := range gjson.Get(res.BodyString, "groups").Array() {
+		inScope := group.Get("in_scope").Bool()
 
 		// Send HTTP request for each table
-
-		for {
-			res2, err = whttp.SendHTTPRequest(
-				&whttp.WHTTPReq{
-					Method: "GET",
-					URL:    "https://bugcrowd.com" + scopeTableURL.String(),
-					Headers: []whttp.WHTTPHeader{
-						{Name: "Cookie", Value: "_crowdcontrol_session_key=" + token},
-						{Name: "User-Agent", Value: USER_AGENT},
-						{Name: "Accept", Value: "*/*"},
-					},
-				}, client)
-
-			if err != nil {
-				utils.Log.Fatal(err)
-			}
-
-			// Rate limiting retry
-			if res2.StatusCode != 429 {
-				break
-			} else {
-				utils.Log.Warn("Hit rate limiting (429), retrying...")
-				time.Sleep(RATE_LIMIT_SLEEP_SECONDS * time.Second)
-			}
+		res2, err := sendRequest(ctx, BUGCROWD_BASE_URL+group.Get("targets_url").String(), token, handle)
+		if err != nil {
+			return pData, err
 		}
 
-		pData.Url = strings.TrimSuffix(handle, "/") + "_bc"
-
 		var program Program
 
 		err = json.Unmarshal([]byte(res2.BodyString), &program)
 
 		if err != nil {
-			utils.Log.Fatal("Could not parse program for handle  ", handle, " with status ", res2.StatusCode)
+			return pData, platforms.SchemaError(PLATFORM_NAME, handle, err)
 		}
 
+		// As for HackerOne, mined host names are listed once per program
 		targets := make(map[string]struct{})
 		for _, target := range program.Targets {
-			catMatches := categories == "all"
-			for _, cat := range GetCategories(categories) {
-				if cat == target.Category {
-					catMatches = true
-					break
-				}
+			element := scope.ScopeElement{
+				Target:      target.Name,
+				Description: target.Description,
+				Category:    categoryMapping.Normalize(target.Category, target.Name),
+				RawCategory: target.Category,
+				AssetID:     target.ID,
+				Source:      scope.SourceIdentifier,
 			}
 
-			if catMatches {
-				for _, match := range r.FindAllString(strings.ToLower(target.Name), -1) {
-					_, ok := targets[match]
-					if !ok {
-						pData.InScope = append(pData.InScope, scope.ScopeElement{Target: match, Description: target.Description, Category: target.Category})
-						targets[match] = struct{}{}
-					}
+			// Some targets carry their address in a separate field
+			var ipElement *scope.ScopeElement
+			if ipAddress := strings.TrimSpace(target.IPAddress); ipAddress != "" && ipAddress != target.Name {
+				ipElement = &scope.ScopeElement{
+					Target:      ipAddress,
+					Description: target.Description,
+					Category:    scope.CategoryCIDR,
+					RawCategory: target.Category,
+					AssetID:     target.ID,
+					Source:      scope.SourceIdentifier,
 				}
-				for _, match := range r.FindAllString(strings.ToLower(target.Description), -1) {
-					_, ok := targets[match]
-					if !ok {
-						pData.InScope = append(pData.InScope, scope.ScopeElement{Target: match, Description: target.Description, Category: target.Category})
-						targets[match] = struct{}{}
-					}
+			}
+
+			// Out-of-scope targets are kept as they are: mining hostnames out of them could exclude too much.
+			// Target groups don't tell which in-scope targets are rewarded, so their eligibility stays unknown.
+			if !inScope {
+				element.BountyEligible = scope.Bool(false)
+				if ipElement != nil {
+					ipElement.BountyEligible = scope.Bool(false)
 				}
-				for _, match := range r.FindAllString(strings.ToLower(target.URI), -1) {
-					_, ok := targets[match]
-					if !ok {
-						pData.InScope = append(pData.InScope, scope.ScopeElement{Target: match, Description: target.Description, Category: target.Category})
-						targets[match] = struct{}{}
-					}
+				pData.OutOfScope = append(pData.OutOfScope, element)
+				if ipElement != nil {
+					pData.OutOfScope = append(pData.OutOfScope, *ipElement)
 				}
+				continue
 			}
-		}
-	}
-
-	/*
-		if noScopeTable {
-			pData.InScope = append(pData.InScope, scope.ScopeElement{Target: "NO_IN_SCOPE_TABLE", Description: "", Category: ""})
-		}
-	*/
-
-	return pData
-}
 
-func GetCategories(input string) []string {
-	categories := map[string][]string{
-		"url":      {"website"},
-		"api":      {"api"},
-		"mobile":   {"android", "ios"},
-		"android":  {"android"},
-		"apple":    {"ios"},
-		"other":    {"other"},
-		"hardware": {"hardware"},
-		"allinfra": {"website", "api", "other"},
-	}
-
-	selectedCategory, ok := categories[strings.ToLower(input)]
-	if !ok {
-		utils.Log.Fatal("Invalid category")
-	}
-	return selectedCategory
-}
+			if ipElement != nil {
+				if _, ok := targets[ipElement.Target]; !ok {
+					pData.InScope = append(pData.InScope, *ipElement)
+					targets[ipElement.Target] = struct{}{}
+				}
+			}
 
-func GetAllProgramsScope(token string, bbpOnly bool, pvtOnly bool, categories string, concurrency int) (programs []scope.ProgramData) {
-	programHandles := GetProgramHandles(token, bbpOnly, pvtOnly)
+			// Mining host names would mangle address ranges, package names and store links, so
+			// network and mobile targets are kept as listed, along with their link for apps
+			if element.Category == scope.CategoryCIDR || element.Category == scope.CategoryAndroid || element.Category == scope.CategoryIOS {
+				identifiers := []string{target.Name}
+				if element.Category != scope.CategoryCIDR {
+					identifiers = append(identifiers, target.URI)
+				}
+				for _, identifier := range identifiers {
+					element.Target = strings.TrimSpace(identifier)
+					if _, ok := targets[element.Target]; !ok && element.Target != "" {
+						pData.InScope = append(pData.InScope, element)
+						targets[element.Target] = struct{}{}
+					}
+				}
+				continue
+			}
 
-	handles := make(chan string, concurrency)
-	processGroup := new(sync.WaitGroup)
-	processGroup.Add(concurrency)
+			for _, field := range []struct {
+				text   string
+				source string
+			}{
+				{target.Name, scope.SourceIdentifier},
+				{target.URI, scope.SourceIdentifier},
+				{target.Description, scope.SourceDescription},
+			} {
+				for _, match := range targetRegex.FindAllString(strings.ToLower(field.text), -1) {
+					if _, ok := targets[match]; ok {
+						continue
+					}
 
-	for i := 0; i < concurrency; i++ {
-		go func() {
-			for {
-				handle := <-handles
+					element.Target = match
+					element.Source = field.source
+					element.RawTarget = ""
+					if field.source == scope.SourceIdentifier && match != field.text {
+						element.RawTarget = field.text
+					}
 
-				if handle == "" {
-					break
+					pData.InScope = append(pData.InScope, element)
+					targets[match] = struct{}{}
 				}
-
-				programs = append(programs, GetProgramScope(handle, categories, token))
 			}
-			processGroup.Done()
-		}()
+		}
 	}
 
-	for _, handle := range programHandles {
-		handles <- handle
-	}
+	return pData, nil
+}
 
-	close(handles)
-	processGroup.Wait()
-	return programs
+// categoryMapping maps the normalized scope categories to Bugcrowd target categories
+var categoryMapping = scope.CategoryMapping{
+	scope.CategoryURL:      {"website"},
+	scope.CategoryWildcard: {"website"},
+	scope.CategoryCIDR:     {"network"},
+	scope.CategoryAPI:      {"api"},
+	scope.CategoryAndroid:  {"android"},
+	scope.CategoryIOS:      {"ios"},
+	scope.CategoryHardware: {"hardware"},
+	scope.CategoryOther:    {"other"},
 }
 
-// PrintAllScope prints to stdout all scope elements of all targets
-func PrintAllScope(token string, bbpOnly bool, pvtOnly bool, categories string, outputFlags string, delimiter string, concurrency int) {
-	programs := GetAllProgramsScope(token, bbpOnly, pvtOnly, categories, concurrency)
-	for _, pData := range programs {
-		scope.PrintProgramScope(pData, outputFlags, delimiter)
-	}
+// GetAllProgramsScope returns the scope of every Bugcrowd program matching opts
+func GetAllProgramsScope(ctx context.Context, token string, opts platforms.Options, concurrency int) ([]scope.ProgramData, error) {
+	return platforms.GetAllProgramsScope(ctx, &Platform{}, platforms.Credentials{"token": token}, opts, concurrency)
 }
 
 /*
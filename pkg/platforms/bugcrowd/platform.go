@@ -0,0 +1,58 @@
+package bugcrowd
+
+import (
+	"context"
+
+	"github.com/sw33tLie/bbscope/pkg/platforms"
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+func init() {
+	platforms.Register(&Platform{})
+}
+
+// Platform implements platforms.Platform for Bugcrowd
+type Platform struct{}
+
+func (*Platform) Name() string        { return "bc" }
+func (*Platform) DisplayName() string { return "Bugcrowd" }
+func (*Platform) URL() string         { return "https://bugcrowd.com/" }
+
+func (*Platform) AuthFields() []platforms.AuthField {
+	return []platforms.AuthField{
+		{Name: "token", Shorthand: "t", Usage: "Bugcrowd session token (_crowdcontrol_session cookie)", ConfigKey: "bugcrowd-token"},
+		{Name: "email", Shorthand: "E", Usage: "Login email", ConfigKey: "bugcrowd-email"},
+		{Name: "password", Shorthand: "P", Usage: "Login password", ConfigKey: "bugcrowd-password"},
+	}
+}
+
+func (*Platform) Categories() scope.CategoryMapping { return categoryMapping }
+
+func (*Platform) DefaultConcurrency() int { return 2 }
+
+// Authenticate logs in with email and password when no session token was provided
+func (*Platform) Authenticate(ctx context.Context, creds platforms.Credentials) (platforms.Credentials, error) {
+	if creds["email"] != "" && creds["password"] != "" && creds["token"] == "" {
+		token, err := Login(ctx, creds["email"], creds["password"])
+		if err != nil {
+			return nil, err
+		}
+		creds["token"] = token
+	}
+	return creds, nil
+}
+
+func (*Platform) ListPrograms(ctx context.Context, creds platforms.Credentials, opts platforms.Options) ([]scope.ProgramData, error) {
+	return GetPrograms(ctx, creds["token"], opts.BbpOnly, opts.PvtOnly)
+}
+
+func (*Platform) GetProgramScope(ctx context.Context, creds platforms.Credentials, program scope.ProgramData, opts platforms.Options) (scope.ProgramData, error) {
+	pData, err := GetProgramScope(ctx, program.Handle, creds["token"])
+	if err != nil {
+		return program, err
+	}
+
+	program.Platform, program.Url = pData.Platform, pData.Url
+	program.InScope, program.OutOfScope = pData.InScope, pData.OutOfScope
+	return program, nil
+}
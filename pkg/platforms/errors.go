@@ -0,0 +1,74 @@
+package platforms
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+// Sentinel errors wrapped by *Error. Use errors.Is to check for them.
+var (
+	ErrUnauthorized     = errors.New("unauthorized")
+	ErrRateLimited      = errors.New("rate limited")
+	ErrNotFound         = errors.New("not found")
+	ErrSchemaChanged    = errors.New("unexpected response format")
+	ErrUnexpectedStatus = errors.New("unexpected HTTP status")
+	ErrInvalidCategory  = scope.ErrInvalidCategory
+)
+
+// Error describes a failure while fetching data from a platform
+type Error struct {
+	Platform string
+	// Handle is the program being fetched, empty when listing programs
+	Handle     string
+	StatusCode int
+	Err        error
+}
+
+func (e *Error) Error() string {
+	msg := e.Platform
+	if e.Handle != "" {
+		msg += " program " + e.Handle
+	}
+	msg += ": " + e.Err.Error()
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(" (status %d)", e.StatusCode)
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// StatusError maps a non-successful HTTP status code to an *Error. It returns nil for 2xx codes.
+func StatusError(platform string, handle string, statusCode int) error {
+	if statusCode >= 200 && statusCode < 300 {
+		return nil
+	}
+
+	var err error
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		err = ErrUnauthorized
+	case http.StatusNotFound:
+		err = ErrNotFound
+	case http.StatusTooManyRequests:
+		err = ErrRateLimited
+	default:
+		err = ErrUnexpectedStatus
+	}
+
+	return &Error{Platform: platform, Handle: handle, StatusCode: statusCode, Err: err}
+}
+
+// SchemaError reports a response that could not be parsed as expected
+func SchemaError(platform string, handle string, cause error) error {
+	err := ErrSchemaChanged
+	if cause != nil {
+		err = fmt.Errorf("%w: %v", ErrSchemaChanged, cause)
+	}
+	return &Error{Platform: platform, Handle: handle, Err: err}
+}
@@ -1,21 +1,23 @@
 package hackerone
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"regexp"
-	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/sw33tLie/bbscope/internal/utils"
+	"github.com/sw33tLie/bbscope/pkg/platforms"
 	"github.com/sw33tLie/bbscope/pkg/scope"
 	"github.com/sw33tLie/bbscope/pkg/whttp"
 	"github.com/tidwall/gjson"
 )
 
 const (
+	PLATFORM_NAME            = "h1"
+	PROGRAM_BASE_URL         = "https://hackerone.com/"
 	RATE_LIMIT_WAIT_TIME_SEC = 5
 	RATE_LIMIT_MAX_RETRIES   = 50
 	RATE_LIMIT_HTTP_STATUS   = 429
@@ -65,193 +67,206 @@ type Program struct {
 	} `json:"relationships,omitempty"`
 }
 
-func getProgramScope(authorization string, id string, bbpOnly bool, categories []string) (pData scope.ProgramData) {
-	r, err := regexp.Compile("(?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\\.)+[a-z0-9][a-z0-9-]{0,61}[a-z0-9]")
-	if err != nil {
-		utils.Log.Fatal("Could not create regex pattern for id ", id)
-	}
-	res := &whttp.WHTTPRes{}
-	lastStatus := -1
+var targetRegex = regexp.MustCompile("(?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\\.)+[a-z0-9][a-z0-9-]{0,61}[a-z0-9]")
+
+// sendRequest sends an authenticated GET request to the HackerOne API, retrying on network errors and rate limiting
+func sendRequest(ctx context.Context, authorization string, url string, handle string) (*whttp.WHTTPRes, error) {
+	var res *whttp.WHTTPRes
+	var err error
 
 	for i := 0; i < RATE_LIMIT_MAX_RETRIES; i++ {
 		res, err = whttp.SendHTTPRequest(
+			ctx,
 			&whttp.WHTTPReq{
 				Method: "GET",
-				URL:    "https://api.hackerone.com/v1/hackers/programs/" + id,
+				URL:    url,
 				Headers: []whttp.WHTTPHeader{
 					{Name: "Authorization", Value: "Basic " + authorization},
 				},
 			}, http.DefaultClient)
 
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
 			utils.Log.Warn("HTTP request failed: ", err, " Retrying...")
-			time.Sleep(2 * time.Second)
+			if err := utils.Sleep(ctx, 2*time.Second); err != nil {
+				return nil, err
+			}
 			continue
 		}
 
-		lastStatus = res.StatusCode
 		// exit the loop if we succeeded
 		if res.StatusCode != RATE_LIMIT_HTTP_STATUS {
 			break
-		} else {
-			// encountered rate limit
-			time.Sleep(RATE_LIMIT_WAIT_TIME_SEC * time.Second)
 		}
+
+		// encountered rate limit
+		if err := utils.Sleep(ctx, RATE_LIMIT_WAIT_TIME_SEC*time.Second); err != nil {
+			return nil, err
+		}
+	}
+
+	if res == nil {
+		return nil, &platforms.Error{Platform: PLATFORM_NAME, Handle: handle, Err: err}
 	}
-	if lastStatus != 200 {
-		// if we completed the requests with a final (non-429) status and we still failed
-		utils.Log.Fatal("Could not retrieve data for id ", id, " with status ", lastStatus)
+
+	// if we completed the requests with a final (non-429) status and we still failed
+	if err := platforms.StatusError(PLATFORM_NAME, handle, res.StatusCode); err != nil {
+		return nil, err
 	}
 
-	pData.Url = id + "_h1"
+	return res, nil
+}
+
+func getProgramScope(ctx context.Context, authorization string, id string, bbpOnly bool) (pData scope.ProgramData, err error) {
+	res, err := sendRequest(ctx, authorization, "https://api.hackerone.com/v1/hackers/programs/"+id, id)
+	if err != nil {
+		return pData, err
+	}
 
 	var program Program
 
 	err = json.Unmarshal([]byte(res.BodyString), &program)
 
 	if err != nil {
-		utils.Log.Fatal("Could not parse program for id  ", id, " with status ", lastStatus)
+		return pData, platforms.SchemaError(PLATFORM_NAME, id, err)
+	}
+
+	pData = programData(program)
+	if pData.Handle == "" {
+		pData.Handle = id
+		pData.Url = PROGRAM_BASE_URL + id
 	}
 
 	l := len(program.Relationships.StructuredScopes.Data)
 
-	isDumpAll := len(categories) == len(getCategories("all"))
+	// Mined host names are listed once per program, the first element keeps its raw identifier
 	targets := make(map[string]struct{})
 	for i := 0; i < l; i++ {
+		structuredScope := program.Relationships.StructuredScopes.Data[i]
+		assetType := structuredScope.Attributes.AssetType
+
+		element := scope.ScopeElement{
+			Target:         structuredScope.Attributes.AssetIdentifier,
+			Description:    strings.ReplaceAll(structuredScope.Attributes.Instruction, "\n", "  "),
+			Category:       categoryMapping.Normalize(assetType, structuredScope.Attributes.AssetIdentifier),
+			RawCategory:    assetType,
+			BountyEligible: scope.Bool(structuredScope.Attributes.EligibleForBounty),
+			MaxSeverity:    structuredScope.Attributes.MaxSeverity,
+			AssetID:        structuredScope.ID,
+			CreatedAt:      structuredScope.Attributes.CreatedAt,
+			UpdatedAt:      structuredScope.Attributes.UpdatedAt,
+			Source:         scope.SourceIdentifier,
+		}
 
-		catFound := false
-		if !isDumpAll {
-			assetCategory := program.Relationships.StructuredScopes.Data[i].Attributes.AssetType
-
-			for _, cat := range categories {
-				if cat == assetCategory {
-					catFound = true
-					break
-				}
-			}
+		// Assets in the OOS table are kept as they are: mining hostnames out of them could exclude too much
+		if !structuredScope.Attributes.EligibleForSubmission {
+			pData.OutOfScope = append(pData.OutOfScope, element)
+			continue
 		}
 
-		if catFound || isDumpAll {
-			// If it's in the in-scope table (and not in the OOS one)
-			if program.Relationships.StructuredScopes.Data[i].Attributes.EligibleForSubmission {
-				if !bbpOnly || (bbpOnly && program.Relationships.StructuredScopes.Data[i].Attributes.EligibleForBounty) {
-					if program.Relationships.StructuredScopes.Data[i].Attributes.AssetType == "DOMAIN" || program.Relationships.StructuredScopes.Data[i].Attributes.AssetType == "URL" || program.Relationships.StructuredScopes.Data[i].Attributes.AssetType == "OTHER" || program.Relationships.StructuredScopes.Data[i].Attributes.AssetType == "WILDCARD" {
-						for _, match := range r.FindAllString(strings.ToLower(program.Relationships.StructuredScopes.Data[i].Attributes.AssetIdentifier), -1) {
-							_, ok := targets[match]
-							if !ok {
-								pData.InScope = append(pData.InScope, scope.ScopeElement{
-									Target:      match,
-									Description: strings.ReplaceAll(program.Relationships.StructuredScopes.Data[i].Attributes.Instruction, "\n", "  "),
-									Category:    "", // TODO
-								})
-								targets[match] = struct{}{}
-							}
-						}
-						for _, match := range r.FindAllString(strings.ToLower(program.Relationships.StructuredScopes.Data[i].Attributes.Instruction), -1) {
-							_, ok := targets[match]
-							if !ok {
-								pData.InScope = append(pData.InScope, scope.ScopeElement{
-									Target:      match,
-									Description: strings.ReplaceAll(program.Relationships.StructuredScopes.Data[i].Attributes.Instruction, "\n", "  "),
-									Category:    "", // TODO
-								})
-								targets[match] = struct{}{}
-							}
+		if !bbpOnly || (bbpOnly && structuredScope.Attributes.EligibleForBounty) {
+			if assetType == "DOMAIN" || assetType == "URL" || assetType == "OTHER" || assetType == "WILDCARD" {
+				identifier := structuredScope.Attributes.AssetIdentifier
+				for _, match := range targetRegex.FindAllString(strings.ToLower(identifier), -1) {
+					_, ok := targets[match]
+					if !ok {
+						element.Target = match
+						if match != identifier {
+							element.RawTarget = identifier
 						}
-					} else {
-						pData.InScope = append(pData.InScope, scope.ScopeElement{
-							Target:      program.Relationships.StructuredScopes.Data[i].Attributes.AssetIdentifier,
-							Description: strings.ReplaceAll(program.Relationships.StructuredScopes.Data[i].Attributes.Instruction, "\n", "  "),
-							Category:    "", // TODO
-						})
+						pData.InScope = append(pData.InScope, element)
+						targets[match] = struct{}{}
+					}
+				}
+				for _, match := range targetRegex.FindAllString(strings.ToLower(structuredScope.Attributes.Instruction), -1) {
+					_, ok := targets[match]
+					if !ok {
+						element.Target = match
+						element.RawTarget = ""
+						element.Source = scope.SourceDescription
+						pData.InScope = append(pData.InScope, element)
+						targets[match] = struct{}{}
 					}
 				}
+			} else {
+				pData.InScope = append(pData.InScope, element)
 			}
 		}
 	}
 
-	/*
-		if l == 0 {
-			pData.InScope = append(pData.InScope, scope.ScopeElement{Target: "NO_IN_SCOPE_TABLE", Description: "", Category: ""})
-		}
-	*/
-
-	return pData
+	return pData, nil
 }
 
-func getCategories(input string) []string {
-	categories := map[string][]string{
-		"domain":     {"DOMAIN"},
-		"wildcard":   {"WILDCARD"},
-		"url":        {"URL"},
-		"cidr":       {"CIDR"},
-		"mobile":     {"GOOGLE_PLAY_APP_ID", "OTHER_APK", "APPLE_STORE_APP_ID"},
-		"android":    {"GOOGLE_PLAY_APP_ID", "OTHER_APK"},
-		"apple":      {"APPLE_STORE_APP_ID"},
-		"other":      {"OTHER"},
-		"hardware":   {"HARDWARE"},
-		"code":       {"SOURCE_CODE"},
-		"executable": {"DOWNLOADABLE_EXECUTABLES"},
-		"all":        {"DOMAIN", "WILDCARD", "URL", "CIDR", "GOOGLE_PLAY_APP_ID", "OTHER_APK", "APPLE_STORE_APP_ID", "OTHER", "HARDWARE", "SOURCE_CODE", "DOWNLOADABLE_EXECUTABLES"},
-		"allinfra":   {"DOMAIN", "WILDCARD", "URL", "CIDR", "OTHER"},
-	}
+// categoryMapping maps the normalized scope categories to HackerOne asset types
+var categoryMapping = scope.CategoryMapping{
+	scope.CategoryURL:        {"DOMAIN", "URL"},
+	scope.CategoryWildcard:   {"WILDCARD"},
+	scope.CategoryCIDR:       {"CIDR", "IP_ADDRESS"},
+	scope.CategoryAndroid:    {"GOOGLE_PLAY_APP_ID", "OTHER_APK"},
+	scope.CategoryIOS:        {"APPLE_STORE_APP_ID", "TESTFLIGHT", "OTHER_IPA"},
+	scope.CategoryExecutable: {"DOWNLOADABLE_EXECUTABLES", "WINDOWS_APP_STORE_APP_ID"},
+	scope.CategoryHardware:   {"HARDWARE"},
+	scope.CategorySourceCode: {"SOURCE_CODE"},
+	scope.CategoryContract:   {"SMART_CONTRACT"},
+	scope.CategoryOther:      {"OTHER", "AI_MODEL"},
+}
 
-	selectedCategory, ok := categories[strings.ToLower(input)]
-	if !ok {
-		utils.Log.Fatal("Invalid category selected")
+// programData converts the attributes of a HackerOne program to a scope.ProgramData, without its scope
+func programData(program Program) scope.ProgramData {
+	return scope.ProgramData{
+		Platform:        PLATFORM_NAME,
+		Handle:          program.Attributes.Handle,
+		Name:            program.Attributes.Name,
+		Url:             PROGRAM_BASE_URL + program.Attributes.Handle,
+		Private:         program.Attributes.State == "soft_launched",
+		OffersBounties:  program.Attributes.OffersBounties,
+		Currency:        strings.ToUpper(program.Attributes.Currency),
+		State:           program.Attributes.State,
+		SubmissionState: program.Attributes.SubmissionState,
+		LaunchDate:      program.Attributes.StartedAcceptingAt,
 	}
-	return selectedCategory
 }
 
-func getProgramHandles(authorization string, pvtOnly bool, publicOnly bool, active bool) (handles []string) {
+func getPrograms(ctx context.Context, authorization string, pvtOnly bool, publicOnly bool, active bool) (programs []scope.ProgramData, err error) {
 	currentURL := "https://api.hackerone.com/v1/hackers/programs"
 	for {
-		res, err := whttp.SendHTTPRequest(
-			&whttp.WHTTPReq{
-				Method: "GET",
-				URL:    currentURL,
-				Headers: []whttp.WHTTPHeader{
-					{Name: "Authorization", Value: "Basic " + authorization},
-				},
-			}, http.DefaultClient)
-
+		res, err := sendRequest(ctx, authorization, currentURL, "")
 		if err != nil {
-			utils.Log.Warn("HTTP request failed: ", err, " Retrying...")
-			time.Sleep(2 * time.Second)
-			continue
+			return nil, err
+		}
+
+		var page struct {
+			Data  []Program `json:"data"`
+			Links struct {
+				Next string `json:"next"`
+			} `json:"links"`
 		}
 
-		if res.StatusCode != 200 {
-			utils.Log.Fatal("Fetching failed. Got status Code: ", res.StatusCode)
+		if err := json.Unmarshal([]byte(res.BodyString), &page); err != nil || !gjson.Get(res.BodyString, "data").IsArray() {
+			return nil, platforms.SchemaError(PLATFORM_NAME, "", err)
 		}
 
-		for i := 0; i < int(gjson.Get(res.BodyString, "data.#").Int()); i++ {
-			handle := gjson.Get(res.BodyString, "data."+strconv.Itoa(i)+".attributes.handle")
+		for _, program := range page.Data {
+			state := program.Attributes.State
 
 			if !publicOnly {
-				if !pvtOnly || (pvtOnly && gjson.Get(res.BodyString, "data."+strconv.Itoa(i)+".attributes.state").Str == "soft_launched") {
-					if active {
-						if gjson.Get(res.BodyString, "data."+strconv.Itoa(i)+".attributes.submission_state").Str == "open" {
-							handles = append(handles, handle.Str)
-						}
-					} else {
-						handles = append(handles, handle.Str)
-					}
-				}
-			} else {
-				if gjson.Get(res.BodyString, "data."+strconv.Itoa(i)+".attributes.state").Str == "public_mode" {
-					if active {
-						if gjson.Get(res.BodyString, "data."+strconv.Itoa(i)+".attributes.submission_state").Str == "open" {
-							handles = append(handles, handle.Str)
-						}
-					} else {
-						handles = append(handles, handle.Str)
-					}
+				if pvtOnly && state != "soft_launched" {
+					continue
 				}
+			} else if state != "public_mode" {
+				continue
+			}
+
+			if active && program.Attributes.SubmissionState != "open" {
+				continue
 			}
+
+			programs = append(programs, programData(program))
 		}
 
-		currentURL = gjson.Get(res.BodyString, "links.next").Str
+		currentURL = page.Links.Next
 
 		// We reached the end
 		if currentURL == "" {
@@ -259,48 +274,11 @@ func getProgramHandles(authorization string, pvtOnly bool, publicOnly bool, acti
 		}
 	}
 
-	return handles
+	return programs, nil
 }
 
-// GetAllProgramsScope xxx
-func GetAllProgramsScope(authorization string, bbpOnly bool, pvtOnly bool, publicOnly bool, categories string, active bool, concurrency int) (programs []scope.ProgramData) {
-	utils.Log.Debug("Fetching list of program handles")
-	programHandles := getProgramHandles(authorization, pvtOnly, publicOnly, active)
-
-	utils.Log.Debug("Fetching scope of each program. Concurrency: ", concurrency)
-	ids := make(chan string, concurrency)
-	processGroup := new(sync.WaitGroup)
-	processGroup.Add(concurrency)
-
-	for i := 0; i < concurrency; i++ {
-		go func() {
-			for {
-				id := <-ids
-
-				if id == "" {
-					break
-				}
-
-				programs = append(programs, getProgramScope(authorization, id, bbpOnly, getCategories(categories)))
-			}
-			processGroup.Done()
-		}()
-	}
-
-	for _, s := range programHandles {
-		ids <- s
-	}
-
-	close(ids)
-	processGroup.Wait()
-
-	return programs
-}
-
-// PrintAllScope prints to stdout all scope elements of all targets
-func PrintAllScope(authorization string, bbpOnly bool, pvtOnly bool, publicOnly bool, categories string, outputFlags string, delimiter string, active bool, concurrency int) {
-	programs := GetAllProgramsScope(authorization, bbpOnly, pvtOnly, publicOnly, categories, active, concurrency)
-	for _, pData := range programs {
-		scope.PrintProgramScope(pData, outputFlags, delimiter)
-	}
+// GetAllProgramsScope returns the scope of every HackerOne program matching opts
+func GetAllProgramsScope(ctx context.Context, username string, token string, opts platforms.Options, concurrency int) ([]scope.ProgramData, error) {
+	creds := platforms.Credentials{"username": username, "token": token}
+	return platforms.GetAllProgramsScope(ctx, &Platform{}, creds, opts, concurrency)
 }
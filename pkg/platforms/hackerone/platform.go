@@ -0,0 +1,44 @@
+package hackerone
+
+import (
+	"context"
+	b64 "encoding/base64"
+
+	"github.com/sw33tLie/bbscope/pkg/platforms"
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+func init() {
+	platforms.Register(&Platform{})
+}
+
+// Platform implements platforms.Platform for HackerOne
+type Platform struct{}
+
+func (*Platform) Name() string        { return "h1" }
+func (*Platform) DisplayName() string { return "HackerOne" }
+func (*Platform) URL() string         { return "https://hackerone.com/" }
+
+func (*Platform) AuthFields() []platforms.AuthField {
+	return []platforms.AuthField{
+		{Name: "username", Shorthand: "u", Usage: "HackerOne username", Required: true, ConfigKey: "hackerone-username"},
+		{Name: "token", Shorthand: "t", Usage: "HackerOne API token, get it here: https://hackerone.com/settings/api_token/edit", Required: true, ConfigKey: "hackerone-token"},
+	}
+}
+
+func (*Platform) Categories() scope.CategoryMapping { return categoryMapping }
+
+func (*Platform) DefaultConcurrency() int { return 3 }
+
+func (*Platform) ListPrograms(ctx context.Context, creds platforms.Credentials, opts platforms.Options) ([]scope.ProgramData, error) {
+	return getPrograms(ctx, authorization(creds), opts.PvtOnly, opts.PublicOnly, opts.ActiveOnly)
+}
+
+func (*Platform) GetProgramScope(ctx context.Context, creds platforms.Credentials, program scope.ProgramData, opts platforms.Options) (scope.ProgramData, error) {
+	return getProgramScope(ctx, authorization(creds), program.Handle, opts.BbpOnly)
+}
+
+// authorization builds the value of the Basic Authorization header used by the HackerOne API
+func authorization(creds platforms.Credentials) string {
+	return b64.StdEncoding.EncodeToString([]byte(creds["username"] + ":" + creds["token"]))
+}
@@ -1,156 +1,150 @@
 package immunefi
 
 import (
-	"log"
+	"context"
 	"net/http"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/sw33tLie/bbscope/pkg/contracts"
+	"github.com/sw33tLie/bbscope/pkg/platforms"
 	"github.com/sw33tLie/bbscope/pkg/scope"
 	"github.com/sw33tLie/bbscope/pkg/whttp"
 	"github.com/tidwall/gjson"
 )
 
 const (
-	PLATFORM_URL = "https://immunefi.com"
+	PLATFORM_NAME = "immunefi"
+	PLATFORM_URL  = "https://immunefi.com"
 )
 
-func PrintAllScope(categories, outputFlags, delimiter string, concurrency int) {
-	programs := GetAllProgramsScope(categories, concurrency)
-	for _, pData := range programs {
-		scope.PrintProgramScope(pData, outputFlags, delimiter)
-	}
+// categoryMapping maps the normalized scope categories to Immunefi asset types
+var categoryMapping = scope.CategoryMapping{
+	scope.CategoryURL:      {"websites_and_applications"},
+	scope.CategoryContract: {"smart_contract"},
+	scope.CategoryOther:    {"blockchain_dlt"},
 }
 
-func getCategories(input string) []string {
-	categories := map[string][]string{
-		"web":       {"websites_and_applications"},
-		"contracts": {"smart_contract"},
-		"all":       {"websites_and_applications", "smart_contract"},
-	}
-
-	selectedCategory, ok := categories[strings.ToLower(input)]
-	if !ok {
-		log.Fatal("Invalid category")
-	}
-	return selectedCategory
-}
-
-func GetAllProgramsScope(categories string, concurrency int) (programs []scope.ProgramData) {
-
+// getNextData fetches an Immunefi page and returns the JSON embedded in its __NEXT_DATA__ script
+func getNextData(ctx context.Context, url string, handle string) (string, error) {
 	res, err := whttp.SendHTTPRequest(
+		ctx,
 		&whttp.WHTTPReq{
 			Method: "GET",
-			URL:    PLATFORM_URL + "/explore/",
+			URL:    url,
 			Headers: []whttp.WHTTPHeader{
 				{Name: "Accept", Value: "*/*"},
 			},
 		}, http.DefaultClient)
 
 	if err != nil {
-		log.Fatal("HTTP request failed: ", err)
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", &platforms.Error{Platform: PLATFORM_NAME, Handle: handle, Err: err}
+	}
+
+	if err := platforms.StatusError(PLATFORM_NAME, handle, res.StatusCode); err != nil {
+		return "", err
 	}
 
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(res.BodyString))
 
 	if err != nil {
-		log.Fatal("Failed to parse HTML")
+		return "", platforms.SchemaError(PLATFORM_NAME, handle, err)
+	}
+
+	nextData := doc.Find("#__NEXT_DATA__")
+	if nextData.Length() == 0 {
+		return "", platforms.SchemaError(PLATFORM_NAME, handle, nil)
 	}
 
-	selectedCategories := getCategories(categories)
+	return nextData.First().Contents().Text(), nil
+}
+
+// programData reads the metadata of a program, as listed both in the explore page and in the program page
+func programData(id string, program gjson.Result) scope.ProgramData {
+	pData := scope.ProgramData{
+		Platform:       PLATFORM_NAME,
+		Handle:         id,
+		Name:           program.Get("project").Str,
+		Url:            PLATFORM_URL + "/bounty/" + id + "/",
+		OffersBounties: true, // Immunefi only hosts bug bounty programs
+		MaxBounty:      program.Get("maximum_reward").Float(),
+		Currency:       "USD",
+	}
+	if launchDate, err := time.Parse(time.RFC3339, program.Get("launchDate").Str); err == nil {
+		pData.LaunchDate = launchDate
+	}
+	return pData
+}
+
+// GetPrograms returns all programs hosted on Immunefi, with the metadata shown in the explore page
+func GetPrograms(ctx context.Context) (programs []scope.ProgramData, err error) {
+	json, err := getNextData(ctx, PLATFORM_URL+"/explore/", "")
+	if err != nil {
+		return nil, err
+	}
 
-	var programURLs []string
-	doc.Find("#__NEXT_DATA__").Each(func(index int, s *goquery.Selection) {
-		json := s.Contents().Text()
-		jsonPrograms := gjson.Get(json, "props.pageProps.bounties")
+	jsonPrograms := gjson.Get(json, "props.pageProps.bounties")
+	if !jsonPrograms.IsArray() {
+		return nil, platforms.SchemaError(PLATFORM_NAME, "", nil)
+	}
 
-		for _, program := range jsonPrograms.Array() {
-			programID := gjson.Get(program.Raw, "id")
-			isExternal := gjson.Get(program.Raw, "is_external").Bool()
+	for _, program := range jsonPrograms.Array() {
+		programID := gjson.Get(program.Raw, "id")
+		isExternal := gjson.Get(program.Raw, "is_external").Bool()
 
-			if !isExternal {
-				programURLs = append(programURLs, PLATFORM_URL+"/bounty/"+programID.Str+"/")
-			}
+		if !isExternal {
+			programs = append(programs, programData(programID.Str, program))
 		}
-	})
-
-	// Iterate over all program pages
-	p := make(chan string, concurrency)
-	processGroup := new(sync.WaitGroup)
-	processGroup.Add(concurrency)
-
-	for i := 0; i < concurrency; i++ {
-		go func() {
-			for {
-				url := <-p
-
-				if url == "" {
-					break
-				}
-
-				res, err := whttp.SendHTTPRequest(
-					&whttp.WHTTPReq{
-						Method: "GET",
-						URL:    url,
-						Headers: []whttp.WHTTPHeader{
-							{Name: "Accept", Value: "*/*"},
-						},
-					}, http.DefaultClient)
-
-				if err != nil {
-					log.Fatal("HTTP request failed: ", err)
-				}
-
-				doc, err := goquery.NewDocumentFromReader(strings.NewReader(res.BodyString))
-
-				if err != nil {
-					log.Fatal("Failed to parse HTML")
-				}
-
-				doc.Find("#__NEXT_DATA__").Each(func(index int, s *goquery.Selection) {
-					json := s.Contents().Text()
-					jsonProgram := gjson.Get(json, "props.pageProps.bounty")
-					var tempScope []scope.ScopeElement
-
-					for _, scopeElement := range gjson.Get(jsonProgram.Raw, "assets").Array() {
-						elementTarget := gjson.Get(scopeElement.Raw, "url").Str
-						elementType := gjson.Get(scopeElement.Raw, "type").Str
-
-						for _, currentCat := range selectedCategories {
-							if currentCat == "websites_and_applications" && strings.Contains(elementType, "websites_and_applications") {
-								tempScope = append(tempScope, scope.ScopeElement{
-									Target:      elementTarget,
-									Description: "",
-									Category:    currentCat,
-								})
-							} else if currentCat == "smart_contract" && strings.Contains(elementType, "smart_contract") {
-								tempScope = append(tempScope, scope.ScopeElement{
-									Target:      elementTarget,
-									Description: "",
-									Category:    currentCat,
-								})
-							}
-						}
-					}
-
-					programs = append(programs, scope.ProgramData{
-						Url:        url,
-						InScope:    tempScope,
-						OutOfScope: nil,
-					})
-				})
-
-			}
-			processGroup.Done()
-		}()
 	}
 
-	for _, pURL := range programURLs {
-		p <- pURL
+	return programs, nil
+}
+
+// GetProgramScope returns the scope of the program with the given ID
+func GetProgramScope(ctx context.Context, id string) (pData scope.ProgramData, err error) {
+	json, err := getNextData(ctx, PLATFORM_URL+"/bounty/"+id+"/", id)
+	if err != nil {
+		return pData, err
+	}
+
+	jsonProgram := gjson.Get(json, "props.pageProps.bounty")
+	if !jsonProgram.Exists() {
+		return pData, platforms.SchemaError(PLATFORM_NAME, id, nil)
+	}
+
+	pData = programData(id, jsonProgram)
+
+	for _, scopeElement := range gjson.Get(jsonProgram.Raw, "assets").Array() {
+		elementTarget := gjson.Get(scopeElement.Raw, "url").Str
+		elementType := gjson.Get(scopeElement.Raw, "type").Str
+
+		element := scope.ScopeElement{
+			Target:         elementTarget,
+			Description:    strings.ReplaceAll(gjson.Get(scopeElement.Raw, "description").Str, "\n", "  "),
+			Category:       categoryMapping.Normalize(elementType, elementTarget),
+			RawCategory:    elementType,
+			BountyEligible: scope.Bool(true), // Immunefi only hosts bug bounty programs
+			AssetID:        gjson.Get(scopeElement.Raw, "id").String(),
+			Source:         scope.SourceIdentifier,
+		}
+		if addedAt, err := time.Parse(time.RFC3339, gjson.Get(scopeElement.Raw, "addedAt").Str); err == nil {
+			element.CreatedAt = addedAt
+		}
+		if contract, ok := contracts.Parse(elementTarget); ok {
+			element.Contract = contract
+		}
+
+		pData.InScope = append(pData.InScope, element)
 	}
 
-	close(p)
-	processGroup.Wait()
-	return programs
+	return pData, nil
+}
+
+// GetAllProgramsScope returns the scope of every Immunefi program
+func GetAllProgramsScope(ctx context.Context, opts platforms.Options, concurrency int) ([]scope.ProgramData, error) {
+	return platforms.GetAllProgramsScope(ctx, &Platform{}, nil, opts, concurrency)
 }
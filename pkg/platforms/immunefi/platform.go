@@ -0,0 +1,53 @@
+package immunefi
+
+import (
+	"context"
+
+	"github.com/sw33tLie/bbscope/pkg/platforms"
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+func init() {
+	platforms.Register(&Platform{})
+}
+
+// Platform implements platforms.Platform for Immunefi. No authentication is needed.
+type Platform struct{}
+
+func (*Platform) Name() string        { return "immunefi" }
+func (*Platform) DisplayName() string { return "Immunefi" }
+func (*Platform) URL() string         { return "https://immunefi.com/explore" }
+
+func (*Platform) AuthFields() []platforms.AuthField { return nil }
+
+func (*Platform) Categories() scope.CategoryMapping { return categoryMapping }
+
+func (*Platform) DefaultConcurrency() int { return 5 }
+
+// ListPrograms lists every program, as Immunefi only hosts public bug bounty programs the
+// bounty and public filters have nothing to drop, while the private one drops everything.
+func (*Platform) ListPrograms(ctx context.Context, creds platforms.Credentials, opts platforms.Options) ([]scope.ProgramData, error) {
+	if opts.PvtOnly {
+		return nil, nil
+	}
+	return GetPrograms(ctx)
+}
+
+func (*Platform) GetProgramScope(ctx context.Context, creds platforms.Credentials, program scope.ProgramData, opts platforms.Options) (scope.ProgramData, error) {
+	pData, err := GetProgramScope(ctx, program.Handle)
+	if err != nil {
+		return program, err
+	}
+
+	// Program pages don't always repeat what the explore page shows
+	if pData.Name == "" {
+		pData.Name = program.Name
+	}
+	if pData.MaxBounty == 0 {
+		pData.MaxBounty = program.MaxBounty
+	}
+	if pData.LaunchDate.IsZero() {
+		pData.LaunchDate = program.LaunchDate
+	}
+	return pData, nil
+}
@@ -1,122 +1,161 @@
 package intigriti
 
 import (
-	"log"
+	"context"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/sw33tLie/bbscope/pkg/platforms"
 	"github.com/sw33tLie/bbscope/pkg/scope"
 	"github.com/sw33tLie/bbscope/pkg/whttp"
 	"github.com/tidwall/gjson"
 )
 
 const (
+	PLATFORM_NAME               = "it"
 	INTIGRITI_PROGRAMS_ENDPOINT = "https://api.intigriti.com/core/researcher/programs"
+	OUT_OF_SCOPE_TIER           = "Out Of Scope"
+	NO_BOUNTY_TIER              = "No Bounty"
 )
 
-func GetCategoryID(input string) []int {
-	categories := map[string][]int{
-		"url":     {1},
-		"cidr":    {4},
-		"mobile":  {2, 3},
-		"android": {2},
-		"apple":   {3},
-		"device":  {5},
-		"other":   {6},
-		"all":     {1, 2, 3, 4, 5, 6},
-	}
+// categoryNames maps Intigriti's numeric asset types to the names shown on the platform
+var categoryNames = map[int]string{
+	1: "url",
+	2: "android",
+	3: "ios",
+	4: "iprange",
+	5: "device",
+	6: "other",
+}
 
-	selectedCategory, ok := categories[strings.ToLower(input)]
-	if !ok {
-		log.Fatal("Invalid category")
-	}
-	return selectedCategory
+// categoryMapping maps the normalized scope categories to Intigriti asset types
+var categoryMapping = scope.CategoryMapping{
+	scope.CategoryURL:      {"url"},
+	scope.CategoryCIDR:     {"iprange"},
+	scope.CategoryAndroid:  {"android"},
+	scope.CategoryIOS:      {"ios"},
+	scope.CategoryHardware: {"device"},
+	scope.CategoryOther:    {"other"},
 }
 
-func GetProgramScope(token string, companyHandle string, programHandle string, categories string) (pData scope.ProgramData) {
-	pData.Url = strings.ReplaceAll("https://www.intigriti.com/researcher/programs/"+companyHandle+"/"+programHandle+"/detail", " ", "%20")
+// programURL returns the page of a program on the Intigriti website
+func programURL(companyHandle string, programHandle string) string {
+	return strings.ReplaceAll("https://www.intigriti.com/researcher/programs/"+companyHandle+"/"+programHandle+"/detail", " ", "%20")
+}
 
+// sendRequest sends an authenticated GET request to the Intigriti API
+func sendRequest(ctx context.Context, url string, token string, handle string) (*whttp.WHTTPRes, error) {
 	res, err := whttp.SendHTTPRequest(
+		ctx,
 		&whttp.WHTTPReq{
 			Method: "GET",
-			URL:    INTIGRITI_PROGRAMS_ENDPOINT + "/" + companyHandle + "/" + programHandle,
+			URL:    url,
 			Headers: []whttp.WHTTPHeader{
 				{Name: "Authorization", Value: "Bearer " + token},
 			},
 		}, http.DefaultClient)
 
 	if err != nil {
-		log.Fatal("HTTP request failed: ", err)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &platforms.Error{Platform: PLATFORM_NAME, Handle: handle, Err: err}
+	}
+
+	if err := platforms.StatusError(PLATFORM_NAME, handle, res.StatusCode); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func GetProgramScope(ctx context.Context, token string, companyHandle string, programHandle string) (pData scope.ProgramData, err error) {
+	pData.Platform = PLATFORM_NAME
+	pData.Handle = companyHandle + "/" + programHandle
+	pData.Url = programURL(companyHandle, programHandle)
+
+	res, err := sendRequest(ctx, INTIGRITI_PROGRAMS_ENDPOINT+"/"+companyHandle+"/"+programHandle, token, companyHandle+"/"+programHandle)
+	if err != nil {
+		return pData, err
+	}
+
+	if !gjson.Get(res.BodyString, "domains").IsArray() {
+		return pData, platforms.SchemaError(PLATFORM_NAME, companyHandle+"/"+programHandle, nil)
 	}
 
 	latestVersionIndex := len(gjson.Get(res.BodyString, "domains.#.content").Array()) - 1
 	currentContent := gjson.Get(res.BodyString, "domains."+strconv.Itoa(latestVersionIndex)+".content")
 
-	chunkData := gjson.GetMany(currentContent.Raw, "#.endpoint", "#.type", "#.description")
-	for i := 0; i < len(chunkData[0].Array()); i++ {
-		selectedCatIDs := GetCategoryID(categories)
-
-		catMatches := false
-		for _, cat := range selectedCatIDs {
-			if cat == int(chunkData[1].Array()[i].Int()) {
-				catMatches = true
-				break
-			}
+	for _, asset := range currentContent.Array() {
+		categoryName, ok := categoryNames[int(asset.Get("type").Int())]
+		if !ok {
+			categoryName = asset.Get("type").String()
 		}
-		if catMatches {
-			pData.InScope = append(pData.InScope, scope.ScopeElement{
-				Target:      chunkData[0].Array()[i].Str,
-				Description: strings.ReplaceAll(chunkData[2].Array()[i].Str, "\n", "  "),
-				Category:    "",
-			})
+		element := scope.ScopeElement{
+			Target:      asset.Get("endpoint").Str,
+			Description: strings.ReplaceAll(asset.Get("description").Str, "\n", "  "),
+			Category:    categoryMapping.Normalize(categoryName, asset.Get("endpoint").Str),
+			RawCategory: categoryName,
+			AssetID:     asset.Get("id").String(),
+			Source:      scope.SourceIdentifier,
 		}
-	}
 
-	if len(pData.InScope) == 0 {
-		pData.InScope = append(pData.InScope, scope.ScopeElement{Target: "NO_IN_SCOPE_TABLE", Description: "", Category: ""})
+		// Bounties depend on the asset's tier, and excluded assets are listed with the "Out Of Scope" tier
+		tier := asset.Get("tier.value").Str
+		if tier != "" {
+			element.BountyEligible = scope.Bool(!strings.EqualFold(tier, NO_BOUNTY_TIER) && !strings.EqualFold(tier, OUT_OF_SCOPE_TIER))
+		}
+		if strings.EqualFold(tier, OUT_OF_SCOPE_TIER) {
+			pData.OutOfScope = append(pData.OutOfScope, element)
+		} else {
+			pData.InScope = append(pData.InScope, element)
+		}
 	}
 
-	return pData
+	return pData, nil
 }
 
-func GetAllProgramsScope(token string, bbpOnly bool, pvtOnly bool, categories string) (programs []scope.ProgramData) {
-	res, err := whttp.SendHTTPRequest(
-		&whttp.WHTTPReq{
-			Method: "GET",
-			URL:    INTIGRITI_PROGRAMS_ENDPOINT,
-			Headers: []whttp.WHTTPHeader{
-				{Name: "Authorization", Value: "Bearer " + token},
-			},
-		}, http.DefaultClient)
-
+// GetPrograms returns the programs matching the filters, with the metadata shown in the programs list.
+// Program handles are in the "companyHandle/programHandle" form.
+func GetPrograms(ctx context.Context, token string, bbpOnly bool, pvtOnly bool) (programs []scope.ProgramData, err error) {
+	res, err := sendRequest(ctx, INTIGRITI_PROGRAMS_ENDPOINT, token, "")
 	if err != nil {
-		log.Fatal("HTTP request failed: ", err)
+		return nil, err
 	}
 
-	data := gjson.GetMany(res.BodyString, "#(type==1)#.companyHandle", "#(type==1)#.handle", "#(type==1)#.maxBounty.value", "#(type==1)#.confidentialityLevel")
-
-	allCompanyHandles := data[0].Array()
-	allHandles := data[1].Array()
-	allMaxBounties := data[2].Array()
-
-	confidentialityLevels := data[3].Array()
+	if !gjson.Parse(res.BodyString).IsArray() {
+		return nil, platforms.SchemaError(PLATFORM_NAME, "", nil)
+	}
 
-	for i := 0; i < len(allHandles); i++ {
-		if !pvtOnly || (pvtOnly && confidentialityLevels[i].Int() == 1) {
-			if !bbpOnly || (bbpOnly && allMaxBounties[i].Float() != 0) {
-				pData := GetProgramScope(token, allCompanyHandles[i].Str, allHandles[i].Str, categories)
-				programs = append(programs, pData)
+	for _, program := range gjson.Get(res.BodyString, "#(type==1)#").Array() {
+		private := program.Get("confidentialityLevel").Int() == 1
+		maxBounty := program.Get("maxBounty.value").Float()
+
+		if !pvtOnly || (pvtOnly && private) {
+			if !bbpOnly || (bbpOnly && maxBounty != 0) {
+				companyHandle := program.Get("companyHandle").Str
+				programHandle := program.Get("handle").Str
+
+				programs = append(programs, scope.ProgramData{
+					Platform:       PLATFORM_NAME,
+					Handle:         companyHandle + "/" + programHandle,
+					Name:           program.Get("name").Str,
+					Url:            programURL(companyHandle, programHandle),
+					Private:        private,
+					OffersBounties: maxBounty != 0,
+					MinBounty:      program.Get("minBounty.value").Float(),
+					MaxBounty:      maxBounty,
+					Currency:       program.Get("maxBounty.currency").Str,
+				})
 			}
 		}
 	}
 
-	return programs
+	return programs, nil
 }
 
-func PrintAllScope(token string, bbpOnly bool, pvtOnly bool, categories string, outputFlags string, delimiter string) {
-	programs := GetAllProgramsScope(token, bbpOnly, pvtOnly, categories)
-	for _, pData := range programs {
-		scope.PrintProgramScope(pData, outputFlags, delimiter)
-	}
+// GetAllProgramsScope returns the scope of every Intigriti program matching opts
+func GetAllProgramsScope(ctx context.Context, token string, opts platforms.Options, concurrency int) ([]scope.ProgramData, error) {
+	return platforms.GetAllProgramsScope(ctx, &Platform{}, platforms.Credentials{"token": token}, opts, concurrency)
 }
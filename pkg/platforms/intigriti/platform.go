@@ -0,0 +1,47 @@
+package intigriti
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sw33tLie/bbscope/pkg/platforms"
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+func init() {
+	platforms.Register(&Platform{})
+}
+
+// Platform implements platforms.Platform for Intigriti
+type Platform struct{}
+
+func (*Platform) Name() string        { return "it" }
+func (*Platform) DisplayName() string { return "Intigriti" }
+func (*Platform) URL() string         { return "https://intigriti.com/" }
+
+func (*Platform) AuthFields() []platforms.AuthField {
+	return []platforms.AuthField{
+		{Name: "token", Shorthand: "t", Usage: "Intigriti Authentication Bearer Token (From api.intigriti.com)", Required: true, ConfigKey: "intigriti-token"},
+	}
+}
+
+func (*Platform) Categories() scope.CategoryMapping { return categoryMapping }
+
+func (*Platform) DefaultConcurrency() int { return 1 }
+
+func (*Platform) ListPrograms(ctx context.Context, creds platforms.Credentials, opts platforms.Options) ([]scope.ProgramData, error) {
+	return GetPrograms(ctx, creds["token"], opts.BbpOnly, opts.PvtOnly)
+}
+
+// GetProgramScope expects program.Handle to be in the "companyHandle/programHandle" form returned by ListPrograms
+func (*Platform) GetProgramScope(ctx context.Context, creds platforms.Credentials, program scope.ProgramData, opts platforms.Options) (scope.ProgramData, error) {
+	companyHandle, programHandle, _ := strings.Cut(program.Handle, "/")
+	pData, err := GetProgramScope(ctx, creds["token"], companyHandle, programHandle)
+	if err != nil {
+		return program, err
+	}
+
+	program.Platform, program.Url = pData.Platform, pData.Url
+	program.InScope, program.OutOfScope = pData.InScope, pData.OutOfScope
+	return program, nil
+}
@@ -0,0 +1,188 @@
+package platforms
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/sw33tLie/bbscope/internal/workerpool"
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+// AuthField describes a single credential a platform needs, such as an API token
+type AuthField struct {
+	// Name is used both as the CLI flag name and as the key in Credentials
+	Name      string
+	Shorthand string
+	Usage     string
+	Required  bool
+	// ConfigKey, when set, allows the value to be read from the config file
+	ConfigKey string
+}
+
+// Credentials maps AuthField names to the values provided by the user
+type Credentials map[string]string
+
+// Options holds the program and scope filters shared by every platform
+type Options struct {
+	BbpOnly    bool `json:"bbp_only"`
+	PvtOnly    bool `json:"pvt_only"`
+	PublicOnly bool `json:"public_only"`
+	ActiveOnly bool `json:"active_only"`
+	// Categories is a comma separated list of the categories and groups accepted by scope.ParseCategories.
+	// Empty selects every category.
+	Categories string `json:"categories"`
+}
+
+// Platform is implemented by every supported bug bounty platform
+type Platform interface {
+	// Name is the short identifier of the platform, also used as subcommand name (e.g. "h1")
+	Name() string
+	// DisplayName is the human readable name of the platform (e.g. "HackerOne")
+	DisplayName() string
+	// URL is the platform's home page
+	URL() string
+	// AuthFields lists the credentials the platform needs
+	AuthFields() []AuthField
+	// Categories maps the normalized scope categories to the platform's own asset types
+	Categories() scope.CategoryMapping
+	// DefaultConcurrency is the suggested number of programs fetched in parallel
+	DefaultConcurrency() int
+	// ListPrograms returns all programs matching opts, with the metadata available
+	// from the platform's listing but without their scope
+	ListPrograms(ctx context.Context, creds Credentials, opts Options) ([]scope.ProgramData, error)
+	// GetProgramScope returns program with its scope filled in. Only program.Handle
+	// is required, so callers can fetch a single program without listing them all.
+	GetProgramScope(ctx context.Context, creds Credentials, program scope.ProgramData, opts Options) (scope.ProgramData, error)
+}
+
+// Authenticator is implemented by platforms that can exchange some credentials
+// (e.g. email and password) for others (e.g. a session token) before fetching data
+type Authenticator interface {
+	Authenticate(ctx context.Context, creds Credentials) (Credentials, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Platform)
+)
+
+// Register makes a platform available to the CLI. It panics if a platform with
+// the same name is already registered.
+func Register(p Platform) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if p == nil {
+		panic("platforms: Register platform is nil")
+	}
+	if _, dup := registry[p.Name()]; dup {
+		panic("platforms: Register called twice for platform " + p.Name())
+	}
+	registry[p.Name()] = p
+}
+
+// Get returns the registered platform with the given name
+func Get(name string) (Platform, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	p, ok := registry[name]
+	return p, ok
+}
+
+// All returns every registered platform, sorted by name
+func All() []Platform {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	all := make([]Platform, 0, len(registry))
+	for _, p := range registry {
+		all = append(all, p)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name() < all[j].Name() })
+	return all
+}
+
+// Result is a single program scope, or error, sent by StreamAllProgramsScope
+type Result struct {
+	// Platform is the name of the platform the result comes from
+	Platform string
+	// Handle is empty for errors not tied to a single program (e.g. a failed listing)
+	Handle  string
+	Program scope.ProgramData
+	Err     error
+}
+
+// StreamAllProgramsScope fetches the scope of every program matching opts, using
+// up to concurrency parallel requests, and sends each one on the returned channel
+// as soon as it and all programs listed before it are available, so the output
+// order matches the platform's listing order. The channel is closed once all
+// programs have been fetched or ctx is done.
+func StreamAllProgramsScope(ctx context.Context, p Platform, creds Credentials, opts Options, concurrency int) <-chan Result {
+	results := make(chan Result)
+
+	send := func(r Result) bool {
+		select {
+		case results <- r:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	go func() {
+		defer close(results)
+
+		categories, err := scope.ParseCategories(opts.Categories)
+		if err != nil {
+			send(Result{Platform: p.Name(), Err: err})
+			return
+		}
+
+		if a, ok := p.(Authenticator); ok {
+			creds, err = a.Authenticate(ctx, creds)
+			if err != nil {
+				send(Result{Platform: p.Name(), Err: err})
+				return
+			}
+		}
+
+		programs, err := p.ListPrograms(ctx, creds, opts)
+		if err != nil {
+			send(Result{Platform: p.Name(), Err: err})
+			return
+		}
+
+		workerpool.Stream(ctx, programs, concurrency, func(ctx context.Context, program scope.ProgramData) (scope.ProgramData, error) {
+			pData, err := p.GetProgramScope(ctx, creds, program, opts)
+			pData.InScope = scope.FilterCategories(pData.InScope, categories)
+			pData.OutOfScope = scope.FilterCategories(pData.OutOfScope, categories)
+			return pData, err
+		}, func(program scope.ProgramData, pData scope.ProgramData, err error) bool {
+			return send(Result{Platform: p.Name(), Handle: program.Handle, Program: pData, Err: err})
+		})
+	}()
+
+	return results
+}
+
+// GetAllProgramsScope fetches the scope of every program matching opts, using
+// up to concurrency parallel requests. Programs that could not be fetched are
+// skipped and their errors joined in the returned error.
+func GetAllProgramsScope(ctx context.Context, p Platform, creds Credentials, opts Options, concurrency int) (programs []scope.ProgramData, err error) {
+	var errs []error
+	for r := range StreamAllProgramsScope(ctx, p, creds, opts, concurrency) {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+			continue
+		}
+		programs = append(programs, r.Program)
+	}
+
+	if ctx.Err() != nil {
+		errs = append(errs, ctx.Err())
+	}
+	return programs, errors.Join(errs...)
+}
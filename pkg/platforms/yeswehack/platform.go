@@ -0,0 +1,37 @@
+package yeswehack
+
+import (
+	"context"
+
+	"github.com/sw33tLie/bbscope/pkg/platforms"
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+func init() {
+	platforms.Register(&Platform{})
+}
+
+// Platform implements platforms.Platform for YesWeHack
+type Platform struct{}
+
+func (*Platform) Name() string        { return "ywh" }
+func (*Platform) DisplayName() string { return "YesWeHack" }
+func (*Platform) URL() string         { return "https://yeswehack.com/" }
+
+func (*Platform) AuthFields() []platforms.AuthField {
+	return []platforms.AuthField{
+		{Name: "token", Shorthand: "t", Usage: "YesWeHack Authorization Bearer Token (From api.yeswehack.com)", Required: true, ConfigKey: "yeswehack-token"},
+	}
+}
+
+func (*Platform) Categories() scope.CategoryMapping { return categoryMapping }
+
+func (*Platform) DefaultConcurrency() int { return 1 }
+
+func (*Platform) ListPrograms(ctx context.Context, creds platforms.Credentials, opts platforms.Options) ([]scope.ProgramData, error) {
+	return GetPrograms(ctx, creds["token"], opts.BbpOnly, opts.PvtOnly)
+}
+
+func (*Platform) GetProgramScope(ctx context.Context, creds platforms.Credentials, program scope.ProgramData, opts platforms.Options) (scope.ProgramData, error) {
+	return GetProgramScope(ctx, creds["token"], program.Handle)
+}
@@ -1,110 +1,180 @@
 package yeswehack
 
 import (
-	"log"
+	"context"
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/sw33tLie/bbscope/pkg/platforms"
 	"github.com/sw33tLie/bbscope/pkg/scope"
 	"github.com/sw33tLie/bbscope/pkg/whttp"
 	"github.com/tidwall/gjson"
 )
 
 const (
+	PLATFORM_NAME                   = "ywh"
 	YESWEHACK_PROGRAMS_ENDPOINT     = "https://api.yeswehack.com/programs" // ?page=1
 	YESWEHACK_PROGRAM_BASE_ENDPOINT = "https://api.yeswehack.com/programs/"
+	YESWEHACK_PROGRAM_WEB_URL       = "https://yeswehack.com/programs/"
+
+	// SCOPE_TYPE_MOBILE is the scope type of apps not tied to a single platform, sorted by mobileCategory
+	SCOPE_TYPE_MOBILE = "mobile-application"
 )
 
-func GetCategoryID(input string) []string {
-	categories := map[string][]string{
-		"url":        {"web-application", "api", "ip-address"},
-		"mobile":     {"mobile-application", "mobile-application-android", "mobile-application-ios"},
-		"android":    {"mobile-application-android"},
-		"apple":      {"mobile-application-ios"},
-		"other":      {"other"},
-		"executable": {"application"},
-		"all":        {"web-application", "api", "ip-address", "mobile-application", "mobile-application-android", "mobile-application-ios", "other", "application"},
+// categoryMapping maps the normalized scope categories to YesWeHack scope types
+var categoryMapping = scope.CategoryMapping{
+	scope.CategoryURL:        {"web-application", "wildcard"},
+	scope.CategoryWildcard:   {"wildcard"},
+	scope.CategoryCIDR:       {"ip-address"},
+	scope.CategoryAPI:        {"api"},
+	scope.CategoryAndroid:    {"mobile-application-android", SCOPE_TYPE_MOBILE},
+	scope.CategoryIOS:        {"mobile-application-ios", SCOPE_TYPE_MOBILE},
+	scope.CategoryExecutable: {"application"},
+	scope.CategoryOther:      {"other"},
+}
+
+// appStoreRegex matches App Store links and numeric App Store IDs
+var appStoreRegex = regexp.MustCompile(`(?i)apple\.com|(?:^|/)id\d{5,}|^\d{5,}$`)
+
+// mobileCategory tells whether a generic mobile application is an iOS or Android app from its
+// target, assuming Android unless it points to the App Store
+func mobileCategory(target string) string {
+	if appStoreRegex.MatchString(strings.TrimSpace(target)) {
+		return scope.CategoryIOS
+	}
+	return scope.CategoryAndroid
+}
+
+// newScopeElement converts a scope entry as returned by the program endpoint
+func newScopeElement(item gjson.Result) scope.ScopeElement {
+	scopeType := item.Get("scope_type").Str
+
+	category := categoryMapping.Normalize(scopeType, item.Get("scope").Str)
+	if strings.EqualFold(scopeType, SCOPE_TYPE_MOBILE) {
+		category = mobileCategory(item.Get("scope").Str)
 	}
 
-	selectedCategory, ok := categories[strings.ToLower(input)]
-	if !ok {
-		log.Fatal("Invalid category")
+	return scope.ScopeElement{
+		Target:      item.Get("scope").Str,
+		Description: "",
+		Category:    category,
+		RawCategory: scopeType,
+		Source:      scope.SourceIdentifier,
 	}
-	return selectedCategory
 }
 
-func GetProgramScope(token string, companySlug string, categories string) (pData scope.ProgramData) {
-	pData.Url = YESWEHACK_PROGRAM_BASE_ENDPOINT + companySlug
+// programData reads the metadata of a program, as returned both by the programs list and the program endpoint
+func programData(program gjson.Result) scope.ProgramData {
+	pData := scope.ProgramData{
+		Platform:       PLATFORM_NAME,
+		Handle:         program.Get("slug").Str,
+		Name:           program.Get("title").Str,
+		Url:            YESWEHACK_PROGRAM_WEB_URL + program.Get("slug").Str,
+		Private:        !program.Get("public").Bool(),
+		OffersBounties: program.Get("bounty").Bool(),
+		MinBounty:      program.Get("bounty_reward_min").Float(),
+		MaxBounty:      program.Get("bounty_reward_max").Float(),
+	}
+	if pData.OffersBounties {
+		pData.Currency = "EUR"
+	}
+	if program.Get("disabled").Bool() {
+		pData.State = "disabled"
+	}
+	return pData
+}
 
+// sendRequest sends an authenticated GET request to the YesWeHack API
+func sendRequest(ctx context.Context, url string, token string, handle string) (*whttp.WHTTPRes, error) {
 	res, err := whttp.SendHTTPRequest(
+		ctx,
 		&whttp.WHTTPReq{
 			Method: "GET",
-			URL:    pData.Url,
+			URL:    url,
 			Headers: []whttp.WHTTPHeader{
 				{Name: "Authorization", Value: "Bearer " + token},
 			},
 		}, http.DefaultClient)
 
 	if err != nil {
-		log.Fatal("HTTP request failed: ", err)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &platforms.Error{Platform: PLATFORM_NAME, Handle: handle, Err: err}
+	}
+
+	if err := platforms.StatusError(PLATFORM_NAME, handle, res.StatusCode); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func GetProgramScope(ctx context.Context, token string, companySlug string) (pData scope.ProgramData, err error) {
+	res, err := sendRequest(ctx, YESWEHACK_PROGRAM_BASE_ENDPOINT+companySlug, token, companySlug)
+	if err != nil {
+		return pData, err
 	}
 
-	chunkData := gjson.GetMany(res.BodyString, "scopes.#.scope", "scopes.#.scope_type")
+	pData = programData(gjson.Parse(res.BodyString))
+	pData.Handle = companySlug
+	pData.Url = YESWEHACK_PROGRAM_WEB_URL + companySlug
 
-	for i := 0; i < len(chunkData[0].Array()); i++ {
-		selectedCatIDs := GetCategoryID(categories)
+	if !gjson.Get(res.BodyString, "scopes").IsArray() {
+		return pData, platforms.SchemaError(PLATFORM_NAME, companySlug, nil)
+	}
 
-		catMatches := false
-		for _, cat := range selectedCatIDs {
-			if cat == chunkData[1].Array()[i].Str {
-				catMatches = true
-				break
-			}
+	// Scopes don't have their own reward settings: every asset of a bounty program is eligible
+	bounty := gjson.Get(res.BodyString, "bounty")
+	for _, item := range gjson.Get(res.BodyString, "scopes").Array() {
+		element := newScopeElement(item)
+		if bounty.Exists() {
+			element.BountyEligible = scope.Bool(bounty.Bool())
 		}
+		pData.InScope = append(pData.InScope, element)
+	}
 
-		if catMatches {
-			pData.InScope = append(pData.InScope, scope.ScopeElement{
-				Target:      chunkData[0].Array()[i].Str,
-				Description: "",
-				Category:    chunkData[1].Array()[i].Str,
+	// Depending on the program, out-of-scope entries are either plain strings or objects like the in-scope ones
+	for _, item := range gjson.Get(res.BodyString, "out_of_scope").Array() {
+		if item.Type == gjson.String {
+			pData.OutOfScope = append(pData.OutOfScope, scope.ScopeElement{
+				Target:         item.Str,
+				Category:       scope.CategoryOther,
+				BountyEligible: scope.Bool(false),
+				Source:         scope.SourceIdentifier,
 			})
+		} else {
+			element := newScopeElement(item)
+			element.BountyEligible = scope.Bool(false)
+			pData.OutOfScope = append(pData.OutOfScope, element)
 		}
 	}
 
-	return pData
+	return pData, nil
 }
 
-func GetAllProgramsScope(token string, bbpOnly bool, pvtOnly bool, categories string) (programs []scope.ProgramData) {
-
+// GetPrograms returns the programs matching the filters, with the metadata shown in the programs list
+func GetPrograms(ctx context.Context, token string, bbpOnly bool, pvtOnly bool) (programs []scope.ProgramData, err error) {
 	var page = 1
 	var nb_pages = 2
 
 	for page <= nb_pages {
-		res, err := whttp.SendHTTPRequest(
-			&whttp.WHTTPReq{
-				Method: "GET",
-				URL:    YESWEHACK_PROGRAMS_ENDPOINT + "?page=" + strconv.Itoa(page),
-				Headers: []whttp.WHTTPHeader{
-					{Name: "Authorization", Value: "Bearer " + token},
-				},
-			}, http.DefaultClient)
-
+		res, err := sendRequest(ctx, YESWEHACK_PROGRAMS_ENDPOINT+"?page="+strconv.Itoa(page), token, "")
 		if err != nil {
-			log.Fatal("HTTP request failed: ", err)
+			return nil, err
 		}
 
-		data := gjson.GetMany(res.BodyString, "items.#.slug", "items.#.bounty", "items.#.public")
-
-		allCompanySlugs := data[0].Array()
-		allRewarding := data[1].Array()
+		if !gjson.Get(res.BodyString, "items").IsArray() {
+			return nil, platforms.SchemaError(PLATFORM_NAME, "", nil)
+		}
 
-		allPublic := data[2].Array()
+		for _, item := range gjson.Get(res.BodyString, "items").Array() {
+			pData := programData(item)
 
-		for i := 0; i < len(allCompanySlugs); i++ {
-			if !pvtOnly || (pvtOnly && !allPublic[i].Bool()) {
-				if !bbpOnly || (bbpOnly && allRewarding[i].Bool()) {
-					pData := GetProgramScope(token, allCompanySlugs[i].Str, categories)
+			if !pvtOnly || (pvtOnly && pData.Private) {
+				if !bbpOnly || (bbpOnly && pData.OffersBounties) {
 					programs = append(programs, pData)
 				}
 			}
@@ -114,12 +184,10 @@ func GetAllProgramsScope(token string, bbpOnly bool, pvtOnly bool, categories st
 		page += 1
 	}
 
-	return programs
+	return programs, nil
 }
 
-func PrintAllScope(token string, bbpOnly bool, pvtOnly bool, categories string, outputFlags string, delimiter string) {
-	programs := GetAllProgramsScope(token, bbpOnly, pvtOnly, categories)
-	for _, pData := range programs {
-		scope.PrintProgramScope(pData, outputFlags, delimiter)
-	}
+// GetAllProgramsScope returns the scope of every YesWeHack program matching opts
+func GetAllProgramsScope(ctx context.Context, token string, opts platforms.Options, concurrency int) ([]scope.ProgramData, error) {
+	return platforms.GetAllProgramsScope(ctx, &Platform{}, platforms.Credentials{"token": token}, opts, concurrency)
 }
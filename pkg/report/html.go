@@ -0,0 +1,120 @@
+package report
+
+import (
+	"html/template"
+	"io"
+)
+
+var htmlTemplate = template.Must(template.New("html").Funcs(template.FuncMap{
+	"bounty":     formatBounty,
+	"date":       formatDate,
+	"visibility": visibility,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>bbscope report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 1200px; padding: 0 1em; color: #222; }
+table { border-collapse: collapse; width: 100%; margin: 1em 0; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f4f4f4; }
+#index th { cursor: pointer; user-select: none; }
+#index th:after { content: " \2195"; color: #aaa; }
+td.num { text-align: right; }
+section { border-top: 2px solid #eee; margin-top: 2em; }
+.meta { display: grid; grid-template-columns: max-content auto; gap: 2px 1em; }
+.meta dt { font-weight: bold; }
+.meta dd { margin: 0; }
+.description { white-space: pre-wrap; color: #555; }
+</style>
+</head>
+<body>
+<h1>bbscope report</h1>
+<p>Generated on {{.Generated.Format "2006-01-02 15:04 MST"}}, {{len .Programs}} programs. Click a column header to sort the index.</p>
+
+<table id="index">
+<thead><tr><th>Program</th><th>Platform</th><th data-type="number">Max bounty</th><th data-type="number">Min bounty</th><th data-type="number">In scope</th><th data-type="number">Out of scope</th></tr></thead>
+<tbody>
+{{- range .Programs}}
+<tr><td><a href="#{{.Anchor}}">{{if .Name}}{{.Name}}{{else}}{{.Handle}}{{end}}</a></td><td>{{.Platform}}</td><td class="num" data-value="{{.MaxBounty}}">{{bounty .MaxBounty .Currency}}</td><td class="num" data-value="{{.MinBounty}}">{{bounty .MinBounty .Currency}}</td><td class="num" data-value="{{len .InScope}}">{{len .InScope}}</td><td class="num" data-value="{{len .OutOfScope}}">{{len .OutOfScope}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{range .Programs}}
+<section id="{{.Anchor}}">
+<h2>{{if .Name}}{{.Name}}{{else}}{{.Handle}}{{end}}</h2>
+<dl class="meta">
+<dt>Platform</dt><dd>{{.Platform}}</dd>
+<dt>Handle</dt><dd>{{.Handle}}</dd>
+<dt>Link</dt><dd><a href="{{.Url}}">{{.Url}}</a></dd>
+<dt>Visibility</dt><dd>{{visibility .Private}}</dd>
+<dt>Offers bounties</dt><dd>{{.OffersBounties}}</dd>
+{{- with bounty .MinBounty .Currency}}
+<dt>Min bounty</dt><dd>{{.}}</dd>{{end}}
+{{- with bounty .MaxBounty .Currency}}
+<dt>Max bounty</dt><dd>{{.}}</dd>{{end}}
+{{- with .State}}
+<dt>State</dt><dd>{{.}}</dd>{{end}}
+{{- with .SubmissionState}}
+<dt>Submission state</dt><dd>{{.}}</dd>{{end}}
+{{- with date .LaunchDate}}
+<dt>Launch date</dt><dd>{{.}}</dd>{{end}}
+{{- with .Categories}}
+<dt>Categories</dt><dd>{{range $i, $c := .}}{{if $i}}, {{end}}{{$c.Category}} ({{$c.Count}}){{end}}</dd>{{end}}
+</dl>
+
+<h3>In scope</h3>
+{{- if .InScope}}
+<table>
+<thead><tr><th>Target</th><th>Category</th><th>Bounty</th><th>Max severity</th><th>Description</th></tr></thead>
+<tbody>
+{{- range .InScope}}
+<tr><td>{{.Target}}</td><td>{{.Category}}</td><td>{{.BountyEligibility}}</td><td>{{.MaxSeverity}}</td><td class="description">{{.Description}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- else}}
+<p>No in-scope assets.</p>
+{{- end}}
+
+<h3>Out of scope</h3>
+{{- if .OutOfScope}}
+<table>
+<thead><tr><th>Target</th><th>Category</th><th>Description</th></tr></thead>
+<tbody>
+{{- range .OutOfScope}}
+<tr><td>{{.Target}}</td><td>{{.Category}}</td><td class="description">{{.Description}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- else}}
+<p>No out-of-scope assets.</p>
+{{- end}}
+</section>
+{{end}}
+<script>
+document.querySelectorAll("#index th").forEach(function (th, column) {
+	var ascending = false;
+	th.addEventListener("click", function () {
+		var tbody = document.querySelector("#index tbody");
+		var numeric = th.dataset.type === "number";
+		ascending = !ascending;
+		Array.from(tbody.rows).sort(function (a, b) {
+			var x = a.cells[column], y = b.cells[column];
+			var order = numeric
+				? parseFloat(x.dataset.value) - parseFloat(y.dataset.value)
+				: x.textContent.localeCompare(y.textContent);
+			return ascending ? order : -order;
+		}).forEach(function (row) { tbody.appendChild(row); });
+	});
+});
+</script>
+</body>
+</html>
+`))
+
+// WriteHTML writes the report as a self-contained HTML page
+func WriteHTML(w io.Writer, r Report) error {
+	return htmlTemplate.Execute(w, r)
+}
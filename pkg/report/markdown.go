@@ -0,0 +1,74 @@
+package report
+
+import (
+	"io"
+	"strings"
+	"text/template"
+)
+
+var markdownTemplate = template.Must(template.New("markdown").Funcs(template.FuncMap{
+	"cell":       markdownCell,
+	"bounty":     formatBounty,
+	"date":       formatDate,
+	"visibility": visibility,
+}).Parse(`# bbscope report
+
+Generated on {{.Generated.Format "2006-01-02 15:04 MST"}}, {{len .Programs}} programs.
+
+## Index
+
+| Program | Platform | Max bounty | Min bounty | In scope | Out of scope |
+| --- | --- | --- | --- | --- | --- |
+{{range .Programs}}| [{{cell .Name}}{{if not .Name}}{{cell .Handle}}{{end}}](#{{.Anchor}}) | {{.Platform}} | {{bounty .MaxBounty .Currency}} | {{bounty .MinBounty .Currency}} | {{len .InScope}} | {{len .OutOfScope}} |
+{{end}}
+{{range .Programs}}
+<a id="{{.Anchor}}"></a>
+
+## {{cell .Name}}{{if not .Name}}{{cell .Handle}}{{end}}
+
+- Platform: {{.Platform}}
+- Handle: {{cell .Handle}}
+- Link: {{.Url}}
+- Visibility: {{visibility .Private}}
+- Offers bounties: {{.OffersBounties}}
+{{- with bounty .MinBounty .Currency}}
+- Min bounty: {{.}}{{end}}
+{{- with bounty .MaxBounty .Currency}}
+- Max bounty: {{.}}{{end}}
+{{- with .State}}
+- State: {{.}}{{end}}
+{{- with .SubmissionState}}
+- Submission state: {{.}}{{end}}
+{{- with date .LaunchDate}}
+- Launch date: {{.}}{{end}}
+{{- with .Categories}}
+- Categories: {{range $i, $c := .}}{{if $i}}, {{end}}{{$c.Category}} ({{$c.Count}}){{end}}{{end}}
+
+### In scope
+{{if .InScope}}
+| Target | Category | Bounty | Max severity | Description |
+| --- | --- | --- | --- | --- |
+{{range .InScope}}| {{cell .Target}} | {{.Category}} | {{.BountyEligibility}} | {{cell .MaxSeverity}} | {{cell .Description}} |
+{{end}}{{else}}
+No in-scope assets.
+{{end}}
+### Out of scope
+{{if .OutOfScope}}
+| Target | Category | Description |
+| --- | --- | --- |
+{{range .OutOfScope}}| {{cell .Target}} | {{.Category}} | {{cell .Description}} |
+{{end}}{{else}}
+No out-of-scope assets.
+{{end}}{{end}}`))
+
+var markdownReplacer = strings.NewReplacer(`\`, `\\`, "|", `\|`, "*", `\*`, "_", `\_`, "\r\n", "<br>", "\n", "<br>", "<", "&lt;", ">", "&gt;")
+
+// markdownCell escapes text so that it fits in a single table cell
+func markdownCell(text string) string {
+	return markdownReplacer.Replace(strings.TrimSpace(text))
+}
+
+// WriteMarkdown writes the report as a Markdown document
+func WriteMarkdown(w io.Writer, r Report) error {
+	return markdownTemplate.Execute(w, r)
+}
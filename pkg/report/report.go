@@ -0,0 +1,95 @@
+package report
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+// CategoryCount is the number of in-scope assets of a category
+type CategoryCount struct {
+	Category string
+	Count    int
+}
+
+// Program is a program as shown in a report
+type Program struct {
+	scope.ProgramData
+	// Anchor identifies the program's section within the report
+	Anchor     string
+	Categories []CategoryCount
+}
+
+// Report holds the data reports are rendered from
+type Report struct {
+	Generated time.Time
+	// Programs are sorted by max bounty, then by number of in-scope assets
+	Programs []Program
+}
+
+var anchorRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// New builds a report of programs
+func New(programs []scope.ProgramData, generated time.Time) Report {
+	r := Report{Generated: generated}
+	anchors := make(map[string]bool)
+
+	for _, pData := range programs {
+		p := Program{ProgramData: pData}
+
+		// Handles differing only in punctuation share an anchor, later ones get a -2, -3... suffix
+		base := strings.Trim(anchorRegex.ReplaceAllString(strings.ToLower(pData.Platform+"-"+pData.Handle), "-"), "-")
+		p.Anchor = base
+		for n := 2; anchors[p.Anchor]; n++ {
+			p.Anchor = base + "-" + strconv.Itoa(n)
+		}
+		anchors[p.Anchor] = true
+
+		counts := make(map[string]int)
+		for _, element := range pData.InScope {
+			counts[element.Category]++
+		}
+		for _, category := range scope.Categories {
+			if counts[category] > 0 {
+				p.Categories = append(p.Categories, CategoryCount{Category: category, Count: counts[category]})
+			}
+		}
+
+		r.Programs = append(r.Programs, p)
+	}
+
+	sort.SliceStable(r.Programs, func(i, j int) bool {
+		a, b := r.Programs[i], r.Programs[j]
+		if a.MaxBounty != b.MaxBounty {
+			return a.MaxBounty > b.MaxBounty
+		}
+		return len(a.InScope) > len(b.InScope)
+	})
+
+	return r
+}
+
+func formatBounty(amount float64, currency string) string {
+	if amount == 0 {
+		return ""
+	}
+	return strings.TrimSpace(strconv.FormatFloat(amount, 'f', -1, 64) + " " + currency)
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+func visibility(private bool) string {
+	if private {
+		return "private"
+	}
+	return "public"
+}
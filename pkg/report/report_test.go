@@ -0,0 +1,107 @@
+package report
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+func elements(categories ...string) []scope.ScopeElement {
+	var elements []scope.ScopeElement
+	for i, category := range categories {
+		elements = append(elements, scope.ScopeElement{Target: category + string(rune('a'+i)) + ".example.com", Category: category})
+	}
+	return elements
+}
+
+func TestNew(t *testing.T) {
+	programs := []scope.ProgramData{
+		{Platform: "h1", Handle: "a.b", MaxBounty: 100, InScope: elements(scope.CategoryURL)},
+		{Platform: "h1", Handle: "A_B", MaxBounty: 5000, InScope: elements(scope.CategoryURL)},
+		{Platform: "h1", Handle: "a-b-2", MaxBounty: 100, InScope: elements(scope.CategoryURL, scope.CategoryWildcard)},
+		{Platform: "bc", Handle: "a-b", InScope: elements(scope.CategoryCIDR, scope.CategoryURL, scope.CategoryCIDR, scope.CategoryOther)},
+		{Platform: "h1", Handle: "a/b", MaxBounty: 100, InScope: elements(scope.CategoryURL, scope.CategoryURL)},
+	}
+
+	r := New(programs, time.Time{})
+
+	var got []string
+	for _, p := range r.Programs {
+		got = append(got, p.Handle+" #"+p.Anchor)
+	}
+	// Sorted by max bounty then number of in-scope assets, ties keep their order
+	want := []string{"A_B #h1-a-b-2", "a-b-2 #h1-a-b-2-2", "a/b #h1-a-b-3", "a.b #h1-a-b", "a-b #bc-a-b"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got programs %v, want %v", got, want)
+	}
+
+	wantCounts := []CategoryCount{{scope.CategoryURL, 1}, {scope.CategoryCIDR, 2}, {scope.CategoryOther, 1}}
+	if counts := r.Programs[4].Categories; !reflect.DeepEqual(counts, wantCounts) {
+		t.Errorf("got category counts %v, want %v", counts, wantCounts)
+	}
+	if counts := New([]scope.ProgramData{{Handle: "empty"}}, time.Time{}).Programs[0].Categories; counts != nil {
+		t.Errorf("got category counts %v for a program without assets", counts)
+	}
+}
+
+func TestMarkdownCell(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"plain", "plain"},
+		{"  padded\n", "padded"},
+		{"a | b", `a \| b`},
+		{`a \| b`, `a \\\| b`},
+		{"line 1\nline 2\r\nline 3", "line 1<br>line 2<br>line 3"},
+		{"*.example.com and api_v2", `\*.example.com and api\_v2`},
+		{"<script>", "&lt;script&gt;"},
+	}
+
+	for _, tt := range tests {
+		if got := markdownCell(tt.text); got != tt.want {
+			t.Errorf("markdownCell(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	r := New([]scope.ProgramData{{
+		Platform: "h1",
+		Handle:   "program",
+		Name:     "Program | Inc",
+		InScope: []scope.ScopeElement{{
+			Target:      "example.com",
+			Category:    scope.CategoryURL,
+			Description: "Main site | prod\nno staging",
+		}},
+	}}, time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC))
+
+	var b strings.Builder
+	if err := WriteMarkdown(&b, r); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"| [Program \\| Inc](#h1-program) | h1 |",
+		`<a id="h1-program"></a>`,
+		"- Categories: url (1)",
+		"| example.com | url |  |  | Main site \\| prod<br>no staging |",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("report does not contain %q:\n%s", want, b.String())
+		}
+	}
+
+	// Every table row must have as many cells as its header
+	for _, line := range strings.Split(b.String(), "\n") {
+		if strings.HasPrefix(line, "| example.com") {
+			if cells := strings.Count(strings.ReplaceAll(line, `\|`, ""), "|"); cells != 6 {
+				t.Errorf("row %q has %d separators, want 6", line, cells)
+			}
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package scope
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var ErrInvalidCategory = errors.New("invalid category")
+
+// Categories lists every normalized category, in display order
+var Categories = []string{
+	CategoryURL,
+	CategoryWildcard,
+	CategoryCIDR,
+	CategoryAPI,
+	CategoryAndroid,
+	CategoryIOS,
+	CategoryExecutable,
+	CategoryHardware,
+	CategorySourceCode,
+	CategoryContract,
+	CategoryOther,
+}
+
+// CategoryGroups are names that select several categories at once
+var CategoryGroups = map[string][]string{
+	"all":      Categories,
+	"allinfra": {CategoryURL, CategoryWildcard, CategoryCIDR, CategoryAPI, CategoryOther},
+	"web":      {CategoryURL, CategoryWildcard, CategoryAPI},
+	"mobile":   {CategoryAndroid, CategoryIOS},
+}
+
+// categoryAliases keeps the platform specific names accepted by older versions working
+var categoryAliases = map[string]string{
+	"apple":     CategoryIOS,
+	"domain":    CategoryURL,
+	"contracts": CategoryContract,
+	"device":    CategoryHardware,
+}
+
+// CategoryNames returns every name accepted by ParseCategories: groups first, then categories
+func CategoryNames() []string {
+	groups := make([]string, 0, len(CategoryGroups))
+	for group := range CategoryGroups {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	return append(groups, Categories...)
+}
+
+// ParseCategories expands a comma separated list of categories and groups into
+// normalized categories. It returns nil when every category is selected, which is also
+// the case of an empty input.
+func ParseCategories(input string) ([]string, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, nil
+	}
+
+	var selected []string
+	seen := make(map[string]bool)
+
+	for _, name := range strings.Split(strings.ToLower(input), ",") {
+		name = strings.TrimSpace(name)
+		if alias, ok := categoryAliases[name]; ok {
+			name = alias
+		}
+
+		expanded, ok := CategoryGroups[name]
+		if !ok {
+			if !isCategory(name) {
+				return nil, fmt.Errorf("%w %q, valid choices are: %s", ErrInvalidCategory, name, strings.Join(CategoryNames(), ", "))
+			}
+			expanded = []string{name}
+		}
+
+		for _, category := range expanded {
+			if !seen[category] {
+				seen[category] = true
+				selected = append(selected, category)
+			}
+		}
+	}
+
+	if len(selected) == len(Categories) {
+		return nil, nil
+	}
+	return selected, nil
+}
+
+func isCategory(name string) bool {
+	for _, category := range Categories {
+		if category == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterCategories returns the elements whose category is among categories.
+// A nil categories slice keeps every element.
+func FilterCategories(elements []ScopeElement, categories []string) []ScopeElement {
+	if categories == nil {
+		return elements
+	}
+
+	var filtered []ScopeElement
+	for _, element := range elements {
+		for _, category := range categories {
+			if element.Category == category {
+				filtered = append(filtered, element)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// CategoryMapping maps normalized categories to the asset types a platform uses for them.
+// Asset types listed under CategoryURL are reported as CategoryWildcard when the target
+// contains a wildcard, so they may be listed under both.
+type CategoryMapping map[string][]string
+
+// Normalize returns the normalized category of a platform asset type, or CategoryOther if it is unknown
+func (m CategoryMapping) Normalize(assetType string, target string) string {
+	for _, category := range Categories {
+		for _, t := range m[category] {
+			if strings.EqualFold(t, assetType) {
+				if category == CategoryURL {
+					return WebCategory(target)
+				}
+				return category
+			}
+		}
+	}
+	return CategoryOther
+}
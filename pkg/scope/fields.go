@@ -0,0 +1,82 @@
+package scope
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Field is a column of scope output, selected with its output flag
+type Field struct {
+	Flag rune
+	// Name is used as column header by tabular formats
+	Name  string
+	Value func(pData ProgramData, element ScopeElement) string
+}
+
+// Fields lists every field that can be selected with the output flags
+var Fields = []Field{
+	{'t', "target", func(p ProgramData, e ScopeElement) string { return e.Target }},
+	{'d', "description", func(p ProgramData, e ScopeElement) string { return e.Description }},
+	{'c', "category", func(p ProgramData, e ScopeElement) string { return e.Category }},
+	{'r', "raw_category", func(p ProgramData, e ScopeElement) string { return e.RawCategory }},
+	{'b', "bounty_eligible", func(p ProgramData, e ScopeElement) string { return e.BountyEligibility() }},
+	{'s', "max_severity", func(p ProgramData, e ScopeElement) string { return e.MaxSeverity }},
+	{'i', "asset_id", func(p ProgramData, e ScopeElement) string { return e.AssetID }},
+	{'f', "created_at", func(p ProgramData, e ScopeElement) string { return formatTime(e.CreatedAt) }},
+	{'m', "updated_at", func(p ProgramData, e ScopeElement) string { return formatTime(e.UpdatedAt) }},
+	{'o', "source", func(p ProgramData, e ScopeElement) string { return e.Source }},
+	{'h', "chain", func(p ProgramData, e ScopeElement) string {
+		if e.Contract == nil {
+			return ""
+		}
+		return e.Contract.Chain
+	}},
+	{'a', "contract_address", func(p ProgramData, e ScopeElement) string {
+		if e.Contract == nil {
+			return ""
+		}
+		return e.Contract.Address
+	}},
+	{'u', "program_url", func(p ProgramData, e ScopeElement) string { return p.Url }},
+	{'P', "platform", func(p ProgramData, e ScopeElement) string { return p.Platform }},
+	{'H', "handle", func(p ProgramData, e ScopeElement) string { return p.Handle }},
+	{'N', "program_name", func(p ProgramData, e ScopeElement) string { return p.Name }},
+	{'V', "visibility", func(p ProgramData, e ScopeElement) string {
+		if p.Private {
+			return "private"
+		}
+		return "public"
+	}},
+	{'B', "offers_bounties", func(p ProgramData, e ScopeElement) string { return strconv.FormatBool(p.OffersBounties) }},
+	{'L', "min_bounty", func(p ProgramData, e ScopeElement) string { return formatBounty(p.MinBounty) }},
+	{'M', "max_bounty", func(p ProgramData, e ScopeElement) string { return formatBounty(p.MaxBounty) }},
+	{'C', "currency", func(p ProgramData, e ScopeElement) string { return p.Currency }},
+	{'S', "state", func(p ProgramData, e ScopeElement) string { return p.State }},
+	{'A', "submission_state", func(p ProgramData, e ScopeElement) string { return p.SubmissionState }},
+	{'D', "launch_date", func(p ProgramData, e ScopeElement) string { return formatTime(p.LaunchDate) }},
+}
+
+var ErrInvalidField = errors.New("invalid print flag")
+
+// ParseFields returns the fields selected by outputFlags, in the same order
+func ParseFields(outputFlags string) ([]Field, error) {
+	var fields []Field
+	for _, f := range outputFlags {
+		field, ok := fieldByFlag(f)
+		if !ok {
+			return nil, fmt.Errorf("%w %q", ErrInvalidField, f)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func fieldByFlag(flag rune) (Field, bool) {
+	for _, field := range Fields {
+		if field.Flag == flag {
+			return field, true
+		}
+	}
+	return Field{}, false
+}
@@ -2,41 +2,155 @@ package scope
 
 import (
 	"fmt"
-	"log"
+	"io"
+	"os"
+	"strconv"
 	"strings"
+	"time"
+)
+
+// Normalized asset categories, shared by all platforms
+const (
+	CategoryURL        = "url"
+	CategoryWildcard   = "wildcard"
+	CategoryCIDR       = "cidr"
+	CategoryAPI        = "api"
+	CategoryAndroid    = "android"
+	CategoryIOS        = "ios"
+	CategoryExecutable = "executable"
+	CategoryHardware   = "hardware"
+	CategorySourceCode = "code"
+	CategoryContract   = "contract"
+	CategoryOther      = "other"
+)
+
+// Where a target was found
+const (
+	SourceIdentifier  = "identifier"
+	SourceDescription = "description"
 )
 
 type ScopeElement struct {
-	Target      string
-	Description string
-	Category    string
+	Target string `json:"target"`
+	// RawTarget is the asset identifier as listed by the platform, set when Target is a host name mined out of it
+	RawTarget   string `json:"raw_target,omitempty"`
+	Description string `json:"description"`
+	// Category is the normalized asset category, one of the Category* constants
+	Category string `json:"category"`
+	// RawCategory is the asset type as returned by the platform
+	RawCategory string `json:"raw_category"`
+	// BountyEligible is nil when the platform doesn't tell whether the asset is eligible for bounties
+	BountyEligible *bool  `json:"bounty_eligible"`
+	MaxSeverity    string `json:"max_severity"`
+	// AssetID is the platform's ID for the asset
+	AssetID   string    `json:"asset_id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// Source tells whether Target is the asset identifier or was mined from its description
+	Source string `json:"source"`
+	// Contract is set for smart contracts whose chain and address are known
+	Contract *Contract `json:"contract,omitempty"`
+}
+
+// Contract is a smart contract deployed on an EVM chain
+type Contract struct {
+	ChainID int64 `json:"chain_id"`
+	// Chain is the chain name (e.g. "ethereum")
+	Chain string `json:"chain"`
+	// Address is EIP-55 checksummed
+	Address string `json:"address"`
+	// ExplorerURL is the block explorer page the contract was found from
+	ExplorerURL string `json:"explorer_url,omitempty"`
 }
 
 type ProgramData struct {
-	Url        string
-	InScope    []ScopeElement
-	OutOfScope []ScopeElement
+	// Platform is the name of the platform hosting the program (e.g. "h1")
+	Platform string `json:"platform"`
+	// Handle identifies the program on its platform
+	Handle string `json:"handle"`
+	Name   string `json:"name"`
+	// Url is the program's page on the platform
+	Url             string         `json:"url"`
+	Private         bool           `json:"private"`
+	OffersBounties  bool           `json:"offers_bounties"`
+	MinBounty       float64        `json:"min_bounty"`
+	MaxBounty       float64        `json:"max_bounty"`
+	Currency        string         `json:"currency"`
+	State           string         `json:"state"`
+	SubmissionState string         `json:"submission_state"`
+	LaunchDate      time.Time      `json:"launch_date"`
+	InScope         []ScopeElement `json:"in_scope"`
+	OutOfScope      []ScopeElement `json:"out_of_scope"`
+}
+
+// Bool returns a pointer to b, for optional fields such as ScopeElement.BountyEligible
+func Bool(b bool) *bool {
+	return &b
+}
+
+// BountyEligibility returns "true" or "false", or an empty string when eligibility is unknown
+func (e ScopeElement) BountyEligibility() string {
+	if e.BountyEligible == nil {
+		return ""
+	}
+	return strconv.FormatBool(*e.BountyEligible)
+}
+
+// WebCategory returns CategoryWildcard for wildcard targets and CategoryURL otherwise
+func WebCategory(target string) string {
+	if strings.Contains(target, "*") {
+		return CategoryWildcard
+	}
+	return CategoryURL
+}
+
+func formatBounty(amount float64) string {
+	if amount == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(amount, 'f', -1, 64)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// PrintProgramScope prints the in-scope elements of a program. It fails with ErrInvalidField on unknown output flags.
+func PrintProgramScope(programScope ProgramData, outputFlags string, delimiter string) error {
+	return FprintProgramScope(os.Stdout, programScope, outputFlags, delimiter)
 }
 
-func PrintProgramScope(programScope ProgramData, outputFlags string, delimiter string) {
+// PrintProgramOutOfScope prints the out-of-scope elements of a program
+func PrintProgramOutOfScope(programScope ProgramData, outputFlags string, delimiter string) error {
+	return FprintProgramOutOfScope(os.Stdout, programScope, outputFlags, delimiter)
+}
+
+// FprintProgramScope writes the in-scope elements of a program to w
+func FprintProgramScope(w io.Writer, programScope ProgramData, outputFlags string, delimiter string) error {
+	return printScopeElements(w, programScope, programScope.InScope, outputFlags, delimiter)
+}
+
+// FprintProgramOutOfScope writes the out-of-scope elements of a program to w
+func FprintProgramOutOfScope(w io.Writer, programScope ProgramData, outputFlags string, delimiter string) error {
+	return printScopeElements(w, programScope, programScope.OutOfScope, outputFlags, delimiter)
+}
+
+func printScopeElements(w io.Writer, programScope ProgramData, elements []ScopeElement, outputFlags string, delimiter string) error {
+	fields, err := ParseFields(outputFlags)
+	if err != nil {
+		return err
+	}
+
 	lines := ""
-	for _, scopeElement := range programScope.InScope {
-		var line string
-		for _, f := range outputFlags {
-			switch f {
-			case 't':
-				line += scopeElement.Target + delimiter
-			case 'd':
-				line += scopeElement.Description + delimiter
-			case 'c':
-				line += scopeElement.Category + delimiter
-			case 'u':
-				line += programScope.Url + delimiter
-			default:
-				log.Fatal("Invalid print flag")
-			}
+	for _, scopeElement := range elements {
+		values := make([]string, 0, len(fields))
+		for _, field := range fields {
+			values = append(values, field.Value(programScope, scopeElement))
 		}
-		line = strings.TrimSuffix(line, delimiter)
+		line := strings.Join(values, delimiter)
 		if len(line) > 0 {
 			lines += line + "\n"
 		}
@@ -45,6 +159,7 @@ func PrintProgramScope(programScope ProgramData, outputFlags string, delimiter s
 	lines = strings.TrimSuffix(lines, "\n")
 
 	if len(lines) > 0 {
-		fmt.Println(lines)
+		_, err = fmt.Fprintln(w, lines)
 	}
+	return err
 }
@@ -0,0 +1,253 @@
+package sqlite
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+
+	// Pure Go driver, so that bbscope keeps building without cgo
+	_ "modernc.org/sqlite"
+)
+
+const schema = `
+PRAGMA foreign_keys = ON;
+
+CREATE TABLE IF NOT EXISTS runs (
+	id          INTEGER PRIMARY KEY,
+	started_at  TEXT NOT NULL,
+	finished_at TEXT,
+	programs    INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS platforms (
+	id   INTEGER PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS categories (
+	id   INTEGER PRIMARY KEY,
+	name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS programs (
+	id                INTEGER PRIMARY KEY,
+	platform_id       INTEGER NOT NULL REFERENCES platforms(id),
+	handle            TEXT NOT NULL,
+	name              TEXT,
+	url               TEXT,
+	private           INTEGER NOT NULL,
+	offers_bounties   INTEGER NOT NULL,
+	min_bounty        REAL,
+	max_bounty        REAL,
+	currency          TEXT,
+	state             TEXT,
+	submission_state  TEXT,
+	launch_date       TEXT,
+	first_seen_run_id INTEGER NOT NULL REFERENCES runs(id),
+	last_seen_run_id  INTEGER NOT NULL REFERENCES runs(id),
+	UNIQUE (platform_id, handle)
+);
+
+CREATE TABLE IF NOT EXISTS targets (
+	id                INTEGER PRIMARY KEY,
+	program_id        INTEGER NOT NULL REFERENCES programs(id) ON DELETE CASCADE,
+	target            TEXT NOT NULL,
+	in_scope          INTEGER NOT NULL,
+	category_id       INTEGER NOT NULL REFERENCES categories(id),
+	raw_category      TEXT,
+	description       TEXT,
+	bounty_eligible   INTEGER,
+	max_severity      TEXT,
+	asset_id          TEXT,
+	source            TEXT,
+	created_at        TEXT,
+	updated_at        TEXT,
+	first_seen_run_id INTEGER NOT NULL REFERENCES runs(id),
+	last_seen_run_id  INTEGER NOT NULL REFERENCES runs(id),
+	UNIQUE (program_id, target, in_scope, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS targets_category ON targets (category_id);
+`
+
+// DB writes programs and their targets to a SQLite database. Each DB is a run: programs
+// and targets already in the database are updated rather than duplicated, and the targets
+// a program no longer lists are removed.
+type DB struct {
+	db         *sql.DB
+	runID      int64
+	categories []string
+	prune      bool
+}
+
+// Open creates the database at path if needed and starts a new run. categories are the
+// categories fetched in this run, as returned by scope.ParseCategories: targets of other
+// categories are left alone when removing the ones programs no longer list. prune must be
+// false when the run's filters drop other targets (e.g. those not eligible for bounties), so
+// that they are not mistaken for targets programs no longer list.
+func Open(path string, categories []string, prune bool) (*DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite handles a single writer anyway, and PRAGMAs are per connection
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	for _, category := range scope.Categories {
+		if _, err := db.Exec(`INSERT OR IGNORE INTO categories (name) VALUES (?)`, category); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	res, err := db.Exec(`INSERT INTO runs (started_at) VALUES (?)`, formatTime(time.Now()))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	runID, err := res.LastInsertId()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &DB{db: db, runID: runID, categories: categories, prune: prune}, nil
+}
+
+// WriteProgram inserts or updates a program along with its in-scope and out-of-scope targets
+func (d *DB) WriteProgram(pData scope.ProgramData) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT OR IGNORE INTO platforms (name) VALUES (?)`, pData.Platform); err != nil {
+		return err
+	}
+
+	var programID int64
+	err = tx.QueryRow(`
+		INSERT INTO programs (platform_id, handle, name, url, private, offers_bounties, min_bounty, max_bounty,
+			currency, state, submission_state, launch_date, first_seen_run_id, last_seen_run_id)
+		VALUES ((SELECT id FROM platforms WHERE name = ?), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (platform_id, handle) DO UPDATE SET
+			name = excluded.name,
+			url = excluded.url,
+			private = excluded.private,
+			offers_bounties = excluded.offers_bounties,
+			min_bounty = excluded.min_bounty,
+			max_bounty = excluded.max_bounty,
+			currency = excluded.currency,
+			state = excluded.state,
+			submission_state = excluded.submission_state,
+			launch_date = excluded.launch_date,
+			last_seen_run_id = excluded.last_seen_run_id
+		RETURNING id`,
+		pData.Platform, pData.Handle, pData.Name, pData.Url, pData.Private, pData.OffersBounties,
+		nullFloat(pData.MinBounty), nullFloat(pData.MaxBounty), pData.Currency, pData.State,
+		pData.SubmissionState, nullTime(pData.LaunchDate), d.runID, d.runID,
+	).Scan(&programID)
+	if err != nil {
+		return err
+	}
+
+	for _, list := range []struct {
+		elements []scope.ScopeElement
+		inScope  bool
+	}{
+		{pData.InScope, true},
+		{pData.OutOfScope, false},
+	} {
+		for _, element := range list.elements {
+			if err := d.upsertTarget(tx, programID, element, list.inScope); err != nil {
+				return err
+			}
+		}
+	}
+
+	if d.prune {
+		if err := d.deleteStaleTargets(tx, programID); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`UPDATE runs SET programs = programs + 1 WHERE id = ?`, d.runID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (d *DB) upsertTarget(tx *sql.Tx, programID int64, element scope.ScopeElement, inScope bool) error {
+	category := element.Category
+	if category == "" {
+		category = scope.CategoryOther
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO targets (program_id, target, in_scope, category_id, raw_category, description, bounty_eligible,
+			max_severity, asset_id, source, created_at, updated_at, first_seen_run_id, last_seen_run_id)
+		VALUES (?, ?, ?, (SELECT id FROM categories WHERE name = ?), ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (program_id, target, in_scope, category_id) DO UPDATE SET
+			raw_category = excluded.raw_category,
+			description = excluded.description,
+			bounty_eligible = excluded.bounty_eligible,
+			max_severity = excluded.max_severity,
+			asset_id = excluded.asset_id,
+			source = excluded.source,
+			created_at = excluded.created_at,
+			updated_at = excluded.updated_at,
+			last_seen_run_id = excluded.last_seen_run_id`,
+		programID, element.Target, inScope, category, element.RawCategory, element.Description,
+		element.BountyEligible, element.MaxSeverity, element.AssetID, element.Source,
+		nullTime(element.CreatedAt), nullTime(element.UpdatedAt), d.runID, d.runID,
+	)
+	return err
+}
+
+// deleteStaleTargets removes the targets of the fetched categories that the program no longer lists
+func (d *DB) deleteStaleTargets(tx *sql.Tx, programID int64) error {
+	query := `DELETE FROM targets WHERE program_id = ? AND last_seen_run_id != ?`
+	args := []interface{}{programID, d.runID}
+
+	if d.categories != nil {
+		query += ` AND category_id IN (SELECT id FROM categories WHERE name IN (?` + strings.Repeat(`, ?`, len(d.categories)-1) + `))`
+		for _, category := range d.categories {
+			args = append(args, category)
+		}
+	}
+
+	_, err := tx.Exec(query, args...)
+	return err
+}
+
+// Close marks the run as finished and closes the database
+func (d *DB) Close() error {
+	_, err := d.db.Exec(`UPDATE runs SET finished_at = ? WHERE id = ?`, formatTime(time.Now()), d.runID)
+	if closeErr := d.db.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func nullTime(t time.Time) sql.NullString {
+	if t.IsZero() {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: formatTime(t), Valid: true}
+}
+
+func nullFloat(f float64) sql.NullFloat64 {
+	return sql.NullFloat64{Float64: f, Valid: f != 0}
+}
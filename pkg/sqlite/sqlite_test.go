@@ -0,0 +1,172 @@
+package sqlite
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+func testProgram(description string, targets ...scope.ScopeElement) scope.ProgramData {
+	for i := range targets {
+		targets[i].Description = description
+	}
+	return scope.ProgramData{
+		Platform:       "h1",
+		Handle:         "program",
+		Name:           "Program " + description,
+		Url:            "https://hackerone.com/program",
+		OffersBounties: true,
+		InScope:        targets,
+		OutOfScope:     []scope.ScopeElement{{Target: "blog.example.com", Category: scope.CategoryURL, Description: description}},
+	}
+}
+
+// writeRun saves programs as a new run in the database at path
+func writeRun(t *testing.T, path string, categories []string, prune bool, programs ...scope.ProgramData) {
+	t.Helper()
+	db, err := Open(path, categories, prune)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pData := range programs {
+		if err := db.WriteProgram(pData); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// targetRows returns target|in_scope|description|first_seen|last_seen for every saved target
+func targetRows(t *testing.T, path string) []string {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT target || '|' || in_scope || '|' || description || '|' || first_seen_run_id || '|' || last_seen_run_id
+		FROM targets ORDER BY target, in_scope`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var result []string
+	for rows.Next() {
+		var row string
+		if err := rows.Scan(&row); err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestWriteProgram(t *testing.T) {
+	wildcard := scope.ScopeElement{Target: "*.example.com", Category: scope.CategoryWildcard, BountyEligible: scope.Bool(true)}
+	api := scope.ScopeElement{Target: "api.example.com", Category: scope.CategoryAPI, BountyEligible: scope.Bool(true)}
+	url := scope.ScopeElement{Target: "example.com", Category: scope.CategoryURL, BountyEligible: scope.Bool(false)}
+
+	tests := []struct {
+		name       string
+		categories []string
+		prune      bool
+		second     scope.ProgramData
+		want       []string
+	}{
+		{
+			name:   "same targets",
+			prune:  true,
+			second: testProgram("v2", wildcard, api, url),
+			want: []string{
+				"*.example.com|1|v2|1|2",
+				"api.example.com|1|v2|1|2",
+				"blog.example.com|0|v2|1|2",
+				"example.com|1|v2|1|2",
+			},
+		},
+		{
+			name:   "new and stale targets",
+			prune:  true,
+			second: testProgram("v2", wildcard, scope.ScopeElement{Target: "new.example.com", Category: scope.CategoryURL}),
+			want: []string{
+				"*.example.com|1|v2|1|2",
+				"blog.example.com|0|v2|1|2",
+				"new.example.com|1|v2|2|2",
+			},
+		},
+		{
+			name:       "other categories kept",
+			categories: []string{scope.CategoryWildcard, scope.CategoryURL},
+			prune:      true,
+			second:     testProgram("v2", wildcard),
+			want: []string{
+				"*.example.com|1|v2|1|2",
+				"api.example.com|1|v1|1|1",
+				"blog.example.com|0|v2|1|2",
+			},
+		},
+		{
+			name:   "bounty filter",
+			prune:  false,
+			second: testProgram("v2", wildcard, api),
+			want: []string{
+				"*.example.com|1|v2|1|2",
+				"api.example.com|1|v2|1|2",
+				"blog.example.com|0|v2|1|2",
+				"example.com|1|v1|1|1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "bbscope.db")
+		writeRun(t, path, nil, true, testProgram("v1", wildcard, api, url))
+		writeRun(t, path, tt.categories, tt.prune, tt.second)
+
+		got := targetRows(t, path)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\ngot  %v\nwant %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWriteProgramUpsert(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bbscope.db")
+	target := scope.ScopeElement{Target: "example.com", Category: scope.CategoryURL}
+	writeRun(t, path, nil, true, testProgram("v1", target))
+	writeRun(t, path, nil, true, testProgram("v2", target), testProgram("v2", target))
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var programs, runs, finished int
+	var name string
+	var firstSeen, lastSeen int64
+	err = db.QueryRow(`SELECT COUNT(*), MAX(name), MIN(first_seen_run_id), MAX(last_seen_run_id) FROM programs`).Scan(&programs, &name, &firstSeen, &lastSeen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if programs != 1 || name != "Program v2" || firstSeen != 1 || lastSeen != 2 {
+		t.Errorf("got %d program(s) named %q seen in runs %d to %d, want 1 named %q seen in runs 1 to 2", programs, name, firstSeen, lastSeen, "Program v2")
+	}
+
+	if err := db.QueryRow(`SELECT COUNT(*), COUNT(finished_at) FROM runs`).Scan(&runs, &finished); err != nil {
+		t.Fatal(err)
+	}
+	if runs != 2 || finished != 2 {
+		t.Errorf("got %d run(s), %d finished, want 2 finished", runs, finished)
+	}
+}
@@ -0,0 +1,241 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/sw33tLie/bbscope/pkg/platforms"
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+const (
+	RUN_FILE       = "run.json"
+	RUN_ID_FORMAT  = "20060102T150405.000000000Z"
+	PROGRAM_SUFFIX = ".json"
+)
+
+var ErrNoRun = errors.New("no matching run in the store")
+
+// Run describes a bbscope run saved in the store
+type Run struct {
+	// ID is the run start time, sortable
+	ID         string            `json:"id"`
+	StartedAt  time.Time         `json:"started_at"`
+	FinishedAt time.Time         `json:"finished_at"`
+	Platforms  []string          `json:"platforms"`
+	Options    platforms.Options `json:"options"`
+	Programs   int               `json:"programs"`
+	// Failed lists, per platform, the programs that could not be fetched. A platform mapped to an
+	// empty handle could not be listed at all.
+	Failed map[string][]string `json:"failed,omitempty"`
+}
+
+// Finished reports whether the run completed, as opposed to being interrupted
+func (r Run) Finished() bool {
+	return !r.FinishedAt.IsZero()
+}
+
+// HasPlatform reports whether the platform was fetched in the run
+func (r Run) HasPlatform(platform string) bool {
+	for _, p := range r.Platforms {
+		if p == platform {
+			return true
+		}
+	}
+	return false
+}
+
+// Store keeps a snapshot of every program fetched by each run, as JSON files laid out as
+// <dir>/<run ID>/<platform>/<program handle>.json
+type Store struct {
+	dir string
+}
+
+// DefaultDir returns the default store location, ~/.bbscope/store
+func DefaultDir() (string, error) {
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".bbscope", "store"), nil
+}
+
+// Open returns the store in dir, creating it if needed
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Runs returns every run in the store, oldest first
+func (s *Store) Runs() ([]Run, error) {
+	entries, err := os.ReadDir(s.dir)
+	// The store directory may have been removed since it was opened, it has no runs then
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []Run
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		run, err := s.Run(entry.Name())
+		if errors.Is(err, ErrNoRun) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	sort.Slice(runs, func(i, j int) bool { return runs[i].ID < runs[j].ID })
+	return runs, nil
+}
+
+// Run returns the run with the given ID
+func (s *Store) Run(id string) (Run, error) {
+	var run Run
+	data, err := os.ReadFile(filepath.Join(s.dir, filepath.Base(id), RUN_FILE))
+	if errors.Is(err, os.ErrNotExist) {
+		return run, fmt.Errorf("%w: %s", ErrNoRun, id)
+	}
+	if err != nil {
+		return run, err
+	}
+	if err := json.Unmarshal(data, &run); err != nil {
+		return run, fmt.Errorf("reading run %s: %w", id, err)
+	}
+	return run, nil
+}
+
+// LatestRun returns the most recent finished run that fetched platform, or any platform if it is empty
+func (s *Store) LatestRun(platform string) (Run, error) {
+	runs, err := s.Runs()
+	if err != nil {
+		return Run{}, err
+	}
+	for i := len(runs) - 1; i >= 0; i-- {
+		if runs[i].Finished() && (platform == "" || runs[i].HasPlatform(platform)) {
+			return runs[i], nil
+		}
+	}
+	return Run{}, ErrNoRun
+}
+
+// Programs returns the programs saved by a run, sorted by platform and handle. If platform
+// is not empty, only its programs are returned.
+func (s *Store) Programs(runID string, platform string) ([]scope.ProgramData, error) {
+	run, err := s.Run(runID)
+	if err != nil {
+		return nil, err
+	}
+
+	var programs []scope.ProgramData
+	for _, p := range run.Platforms {
+		if platform != "" && p != platform {
+			continue
+		}
+
+		entries, err := os.ReadDir(filepath.Join(s.dir, run.ID, p))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), PROGRAM_SUFFIX) {
+				continue
+			}
+			pData, err := readProgram(filepath.Join(s.dir, run.ID, p, entry.Name()))
+			if err != nil {
+				return nil, err
+			}
+			programs = append(programs, pData)
+		}
+	}
+
+	sort.Slice(programs, func(i, j int) bool {
+		if programs[i].Platform != programs[j].Platform {
+			return programs[i].Platform < programs[j].Platform
+		}
+		return programs[i].Handle < programs[j].Handle
+	})
+	return programs, nil
+}
+
+// Program returns a program as saved by a run
+func (s *Store) Program(runID string, platform string, handle string) (scope.ProgramData, error) {
+	return readProgram(s.programPath(runID, platform, handle))
+}
+
+// History returns the program as saved by every run that fetched it, oldest first
+func (s *Store) History(platform string, handle string) ([]Run, []scope.ProgramData, error) {
+	runs, err := s.Runs()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var found []Run
+	var programs []scope.ProgramData
+	for _, run := range runs {
+		pData, err := s.Program(run.ID, platform, handle)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		found = append(found, run)
+		programs = append(programs, pData)
+	}
+	return found, programs, nil
+}
+
+func (s *Store) programPath(runID string, platform string, handle string) string {
+	// Handles may contain slashes (e.g. Intigriti's "company/program")
+	return filepath.Join(s.dir, filepath.Base(runID), filepath.Base(platform), url.PathEscape(handle)+PROGRAM_SUFFIX)
+}
+
+func readProgram(path string) (pData scope.ProgramData, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return pData, err
+	}
+	if err := json.Unmarshal(data, &pData); err != nil {
+		return pData, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return pData, nil
+}
+
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that readers never see half written files
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
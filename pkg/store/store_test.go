@@ -0,0 +1,185 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sw33tLie/bbscope/pkg/platforms"
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+func TestRoundTrip(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "missing", "store"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	programs := []scope.ProgramData{
+		{
+			Platform: "it",
+			Handle:   "company/program",
+			Name:     "Program",
+			InScope: []scope.ScopeElement{
+				{Target: "example.com", RawTarget: "*.example.com", Category: scope.CategoryWildcard, BountyEligible: scope.Bool(false)},
+				{Target: "api.example.com", Category: scope.CategoryAPI},
+			},
+			LaunchDate: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		{Platform: "it", Handle: "company/other", OffersBounties: true, MaxBounty: 1000},
+		{Platform: "h1", Handle: "..", Private: true},
+		{Platform: "h1", Handle: "a%2Fb"},
+	}
+
+	opts := platforms.Options{BbpOnly: true, Categories: "web"}
+	w, err := s.NewRun([]string{"h1", "it"}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pData := range programs {
+		if err := w.WriteProgram(pData); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.WriteError("it", "company/broken", errors.New("boom"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	run, err := s.Run(w.Run().ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !run.Finished() || run.Programs != len(programs) || run.Options != opts || !reflect.DeepEqual(run.Failed, map[string][]string{"it": {"company/broken"}}) {
+		t.Errorf("unexpected run %+v", run)
+	}
+
+	got, err := s.Programs(run.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []scope.ProgramData{programs[2], programs[3], programs[1], programs[0]}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got programs\n%+v\nwant\n%+v", got, want)
+	}
+
+	got, err = s.Programs(run.ID, "h1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Handle != ".." || got[1].Handle != "a%2Fb" {
+		t.Errorf("got h1 programs %+v", got)
+	}
+
+	pData, err := s.Program(run.ID, "it", "company/program")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(pData, programs[0]) {
+		t.Errorf("got program %+v, want %+v", pData, programs[0])
+	}
+
+	// Handles must not escape the platform directory
+	if _, err := os.Stat(filepath.Join(s.dir, run.ID, "company")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("a handle with a slash created a directory: %v", err)
+	}
+}
+
+func TestRuns(t *testing.T) {
+	s, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.LatestRun(""); !errors.Is(err, ErrNoRun) {
+		t.Errorf("LatestRun on an empty store = %v, want ErrNoRun", err)
+	}
+	if _, err := s.Run("20240101T000000.000000000Z"); !errors.Is(err, ErrNoRun) {
+		t.Errorf("Run of a missing ID = %v, want ErrNoRun", err)
+	}
+
+	newRun := func(platformNames []string, finish bool) Run {
+		w, err := s.NewRun(platformNames, platforms.Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if finish {
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// Run IDs are timestamps
+		time.Sleep(time.Millisecond)
+		return w.Run()
+	}
+	first := newRun([]string{"h1"}, true)
+	second := newRun([]string{"bc"}, true)
+	newRun([]string{"h1", "bc"}, false)
+
+	// Directories that are not runs are ignored
+	if err := os.Mkdir(filepath.Join(s.dir, "not-a-run"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := s.Runs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 3 || runs[0].ID != first.ID || runs[1].ID != second.ID || runs[2].Finished() {
+		t.Errorf("got runs %+v", runs)
+	}
+
+	for _, tt := range []struct {
+		platform string
+		want     string
+	}{
+		{"", second.ID},
+		{"h1", first.ID},
+		{"bc", second.ID},
+	} {
+		run, err := s.LatestRun(tt.platform)
+		if err != nil || run.ID != tt.want {
+			t.Errorf("LatestRun(%q) = %s, %v, want %s", tt.platform, run.ID, err, tt.want)
+		}
+	}
+	if _, err := s.LatestRun("it"); !errors.Is(err, ErrNoRun) {
+		t.Errorf("LatestRun of a platform never fetched = %v, want ErrNoRun", err)
+	}
+
+	runs, programs, err := s.History("h1", "program")
+	if err != nil || runs != nil || programs != nil {
+		t.Errorf("History of a missing program = %v, %v, %v", runs, programs, err)
+	}
+}
+
+func TestMissingStoreDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "store")
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := s.Runs()
+	if err != nil || runs != nil {
+		t.Errorf("Runs = %v, %v, want no run", runs, err)
+	}
+	if _, err := s.LatestRun(""); !errors.Is(err, ErrNoRun) {
+		t.Errorf("LatestRun = %v, want ErrNoRun", err)
+	}
+	w, err := s.NewRun([]string{"h1"}, platforms.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if run, err := s.LatestRun("h1"); err != nil || run.ID != w.Run().ID {
+		t.Errorf("LatestRun = %s, %v, want the new run %s", run.ID, err, w.Run().ID)
+	}
+}
@@ -0,0 +1,68 @@
+package store
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sw33tLie/bbscope/pkg/platforms"
+	"github.com/sw33tLie/bbscope/pkg/scope"
+)
+
+// RunWriter saves the programs of a new run as they are fetched
+type RunWriter struct {
+	store *Store
+	run   Run
+}
+
+// NewRun starts a run fetching the given platforms with opts
+func (s *Store) NewRun(platformNames []string, opts platforms.Options) (*RunWriter, error) {
+	now := time.Now().UTC()
+	w := &RunWriter{
+		store: s,
+		run: Run{
+			ID:        now.Format(RUN_ID_FORMAT),
+			StartedAt: now,
+			Platforms: platformNames,
+			Options:   opts,
+		},
+	}
+
+	// The store directory is created again if it was removed since it was opened
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := os.Mkdir(filepath.Join(s.dir, w.run.ID), 0755); err != nil {
+		return nil, err
+	}
+	return w, w.writeRun()
+}
+
+// Run returns the run being written
+func (w *RunWriter) Run() Run {
+	return w.run
+}
+
+func (w *RunWriter) WriteProgram(pData scope.ProgramData) error {
+	w.run.Programs++
+	return writeJSON(w.store.programPath(w.run.ID, pData.Platform, pData.Handle), pData)
+}
+
+// WriteError records a program, or a whole platform if handle is empty, that could not be fetched
+func (w *RunWriter) WriteError(platform string, handle string, err error) error {
+	if w.run.Failed == nil {
+		w.run.Failed = make(map[string][]string)
+	}
+	w.run.Failed[platform] = append(w.run.Failed[platform], handle)
+	return nil
+}
+
+// Close marks the run as finished
+func (w *RunWriter) Close() error {
+	w.run.FinishedAt = time.Now().UTC()
+	return w.writeRun()
+}
+
+func (w *RunWriter) writeRun() error {
+	return writeJSON(filepath.Join(w.store.dir, w.run.ID, RUN_FILE), w.run)
+}
@@ -1,6 +1,7 @@
 package whttp
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -29,9 +30,9 @@ type WHTTPRes struct {
 	BodyString     string
 }
 
-func SendHTTPRequest(wReq *WHTTPReq, client *http.Client) (wRes *WHTTPRes, err error) {
+func SendHTTPRequest(ctx context.Context, wReq *WHTTPReq, client *http.Client) (wRes *WHTTPRes, err error) {
 	var req *http.Request
-	req, err = http.NewRequest(wReq.Method, wReq.URL, nil)
+	req, err = http.NewRequestWithContext(ctx, wReq.Method, wReq.URL, nil)
 
 	if err != nil {
 		return nil, err